@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/torbenconto/gopwd/internal/audit"
+	"github.com/torbenconto/gopwd/internal/breach"
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/entry"
+	"github.com/torbenconto/gopwd/internal/io"
+	"github.com/torbenconto/gopwd/internal/strength"
+	"github.com/torbenconto/gopwd/internal/util"
+)
+
+var auditCmd = &cobra.Command{
+	Use:               "audit [subtree] [flags]",
+	Short:             "Report reused, short, weak and old passwords",
+	Long:              "Decrypt every entry in the vault, or below a subtree, and list those whose password is reused, short, easy to guess or has not changed for a long time, the most serious first. Strength is estimated offline.",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: AutocompleteServices,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var subtree string
+		if len(args) > 0 {
+			subtree = args[0]
+		}
+
+		services, err := util.ListServicesUnder(VaultPath, subtree)
+		if err != nil {
+			return fmt.Errorf("failed to list services: %v", err)
+		}
+
+		jsonFlag, _ := cmd.Flags().GetBool("json")
+		minLength, _ := cmd.Flags().GetInt("min-length")
+		minBits, _ := cmd.Flags().GetFloat64("min-bits")
+		maxAge, _ := cmd.Flags().GetInt("max-age")
+		workers, _ := cmd.Flags().GetInt("workers")
+		breachDB, _ := cmd.Flags().GetString("breach-db")
+		if !cmd.Flags().Changed("breach-db") {
+			breachDB = viper.GetString("breachDB")
+		}
+
+		var db *breach.DB
+		if breachDB != "" {
+			db, err = breach.Open(breachDB)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+		}
+
+		backends := util.NewBackendCache(VaultPath, crypt.Config{})
+
+		var mu sync.Mutex
+		var entries []audit.Entry
+
+		errs := util.ForEachService(services, workers, func(service string) error {
+			backend, err := backends.Get(service)
+			if err != nil {
+				return err
+			}
+
+			servicePath := util.ServicePath(VaultPath, service)
+			info, err := io.Stat(servicePath)
+			if err != nil {
+				return fmt.Errorf("failed to stat file: %v", err)
+			}
+
+			file, err := io.ReadFile(servicePath)
+			if err != nil {
+				return fmt.Errorf("failed to read file: %v", err)
+			}
+
+			content, err := backend.Decrypt(file)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt password: %v", err)
+			}
+
+			e := audit.Entry{
+				Service:  service,
+				Password: entry.Parse(content).Password(),
+				Modified: info.ModTime(),
+			}
+			if db != nil {
+				e.Breaches, err = db.Count(e.Password)
+				if err != nil {
+					return fmt.Errorf("failed to search breach database: %v", err)
+				}
+			}
+
+			mu.Lock()
+			entries = append(entries, e)
+			mu.Unlock()
+
+			return nil
+		}, func(done, total int, service string, err error) {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[%d/%d] %s: %v\n", done, total, service, err)
+			}
+		})
+		if len(errs) > 0 {
+			return fmt.Errorf("failed to decrypt %d of %d entries", len(errs), len(services))
+		}
+
+		report := audit.Run(entries, audit.Options{
+			MinLength: minLength,
+			MinBits:   minBits,
+			MaxAge:    time.Duration(maxAge) * 24 * time.Hour,
+		}, time.Now())
+
+		if jsonFlag {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			encoder.SetEscapeHTML(false)
+			return encoder.Encode(report)
+		}
+
+		if len(report.Findings) > 0 {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SERVICE\tSTRENGTH\tBITS\tLENGTH\tAGE\tISSUES")
+			for _, finding := range report.Findings {
+				issues := strings.Join(finding.Issues, ", ")
+				if finding.Breaches > 0 {
+					issues += fmt.Sprintf(" (seen %d times in breaches)", finding.Breaches)
+				}
+				if len(finding.ReusedWith) > 0 {
+					issues += " (also " + strings.Join(finding.ReusedWith, ", ") + ")"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%.0f\t%d\t%dd\t%s\n", finding.Service, strength.ScoreNames[finding.Strength.Score], finding.Strength.Bits, finding.Length, finding.AgeDays, issues)
+			}
+			w.Flush()
+		}
+
+		fmt.Printf("%d of %d entries have issues\n", len(report.Findings), report.Audited)
+
+		return nil
+	},
+}
+
+func init() {
+	auditCmd.Flags().Bool("json", false, "Print the report as JSON")
+	auditCmd.Flags().Int("min-length", 12, "Passwords shorter than this are reported as short")
+	auditCmd.Flags().Float64("min-bits", 40, "Passwords with a lower estimated entropy are reported as weak")
+	auditCmd.Flags().Int("max-age", 365, "Entries unchanged for more days are reported as old, 0 to skip the check")
+	auditCmd.Flags().String("breach-db", "", "Sorted Pwned Passwords SHA-1 file to check passwords against (default from breachDB in the config)")
+	auditCmd.Flags().IntP("workers", "w", util.DefaultWorkers, "Number of entries to decrypt at once")
+	rootCmd.AddCommand(auditCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/torbenconto/gopwd/internal/clip"
+)
+
+var clipRestoreCmd = &cobra.Command{
+	Use:    clip.RestoreCommand,
+	Short:  "Restore the clipboard after a copied secret times out",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return clip.Restore(os.Stdin)
+	},
+}
+
+// addClipTimeoutFlag adds the --clip-timeout flag to a command that copies secrets.
+func addClipTimeoutFlag(cmd *cobra.Command) {
+	cmd.Flags().Int("clip-timeout", int(clip.DefaultTimeout.Seconds()), "Seconds before the clipboard is restored, 0 to keep the secret (default from clipTimeout in the config)")
+}
+
+// copySecret copies secret to the clipboard and prints message followed by when it will be cleared.
+// The --clip-timeout flag takes precedence over clipTimeout in the config.
+func copySecret(cmd *cobra.Command, secret, message string) error {
+	timeout := clip.DefaultTimeout
+	if cmd.Flags().Changed("clip-timeout") {
+		seconds, _ := cmd.Flags().GetInt("clip-timeout")
+		timeout = time.Duration(seconds) * time.Second
+	} else if viper.IsSet("clipTimeout") {
+		timeout = time.Duration(viper.GetInt("clipTimeout")) * time.Second
+	}
+
+	err := clip.Copy(secret, timeout)
+	if err != nil {
+		return err
+	}
+
+	if timeout > 0 {
+		fmt.Printf("%s, clearing in %d seconds\n", message, int(timeout.Seconds()))
+	} else {
+		fmt.Println(message)
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(clipRestoreCmd)
+}
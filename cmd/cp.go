@@ -2,12 +2,14 @@ package cmd
 
 import (
 	"fmt"
-	"path"
 
 	"github.com/spf13/cobra"
 
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/history"
 	"github.com/torbenconto/gopwd/internal/io"
 	"github.com/torbenconto/gopwd/internal/termio"
+	"github.com/torbenconto/gopwd/internal/util"
 )
 
 var cpCmd = &cobra.Command{
@@ -20,8 +22,8 @@ var cpCmd = &cobra.Command{
 		service := args[0]
 		newService := args[1]
 
-		servicePath := path.Join(VaultPath, service) + ".gpg"
-		newServicePath := path.Join(VaultPath, newService) + ".gpg"
+		servicePath := util.ServicePath(VaultPath, service)
+		newServicePath := util.ServicePath(VaultPath, newService)
 
 		// Check if service exists
 		if !io.Exists(servicePath) {
@@ -30,20 +32,26 @@ var cpCmd = &cobra.Command{
 
 		// Check if new service already exists
 		if io.Exists(newServicePath) {
-			fmt.Println("There is already a service at this name, would you like to overwrite it? This action cannot be undone.")
+			fmt.Println("There is already a service at this name, would you like to overwrite it? The current entry is kept in its history.")
 			if confirm, _ := termio.ConfirmAction(); confirm {
-				err := io.CopyFile(servicePath, newServicePath)
+				err := history.Save(VaultPath, newService, newServicePath)
+				if err != nil {
+					return err
+				}
+				err = util.CopyService(VaultPath, service, newService, crypt.Config{})
 				if err != nil {
 					return fmt.Errorf("failed to copy file: %v", err)
 				}
+				commitVault("Copy %s to %s", service, newService)
 			} else {
 				fmt.Println("Aborted")
 			}
 		} else {
-			err := io.CopyFile(servicePath, newServicePath)
+			err := util.CopyService(VaultPath, service, newService, crypt.Config{})
 			if err != nil {
 				return fmt.Errorf("failed to copy file: %v", err)
 			}
+			commitVault("Copy %s to %s", service, newService)
 			fmt.Printf("Copied %s to %s\n", service, newService)
 		}
 
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/torbenconto/gopwd/internal/crypt/openpgp"
+	"github.com/torbenconto/gopwd/internal/doctor"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Command palette for checking the health of the vault",
+
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var doctorRecipientsCmd = &cobra.Command{
+	Use:               "recipients [subtree] [flags]",
+	Short:             "List entries encrypted to stale recipients",
+	Long:              "Compare the keys each entry is encrypted to with the recipients in its nearest .gpg-id, without decrypting anything. Fix stale entries with 'gopwd reencrypt'.",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: AutocompleteServices,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var subtree string
+		if len(args) > 0 {
+			subtree = args[0]
+		}
+
+		jsonFlag, _ := cmd.Flags().GetBool("json")
+		keyringFlag, _ := cmd.Flags().GetString("keyring")
+
+		// Use the public keys gpg knows about unless told otherwise
+		if keyringFlag == "" {
+			keyringFlag = viper.GetString("keyring")
+		}
+		if keyringFlag == "" {
+			keyringFlag = openpgp.DefaultKeyring()
+		}
+
+		keyring, err := openpgp.LoadKeyring(keyringFlag)
+		if err != nil {
+			return err
+		}
+
+		report, err := doctor.CheckRecipients(VaultPath, subtree, keyring)
+		if err != nil {
+			return fmt.Errorf("failed to check recipients: %v", err)
+		}
+
+		if jsonFlag {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			encoder.SetEscapeHTML(false)
+			return encoder.Encode(report)
+		}
+
+		for _, warning := range report.Warnings {
+			fmt.Fprintln(os.Stderr, "warning:", warning)
+		}
+
+		for _, entry := range report.Stale {
+			fmt.Printf("%s (%s)\n", entry.Service, entry.RecipientsFile)
+			if entry.Error != "" {
+				fmt.Printf("    error:   %s\n", entry.Error)
+			}
+			if len(entry.Missing) > 0 {
+				fmt.Printf("    missing: %s\n", strings.Join(entry.Missing, ", "))
+			}
+			if len(entry.Extra) > 0 {
+				fmt.Printf("    extra:   %s\n", strings.Join(entry.Extra, ", "))
+			}
+		}
+
+		fmt.Printf("%d of %d entries have stale recipients\n", len(report.Stale), report.Checked)
+
+		return nil
+	},
+}
+
+func init() {
+	doctorRecipientsCmd.Flags().Bool("json", false, "Print the report as JSON")
+	doctorRecipientsCmd.Flags().String("keyring", "", "Keyring used to look up recipients (default is $GNUPGHOME)")
+	doctorCmd.AddCommand(doctorRecipientsCmd)
+	rootCmd.AddCommand(doctorCmd)
+}
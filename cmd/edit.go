@@ -4,12 +4,12 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path"
 
 	"github.com/spf13/cobra"
 
-	"github.com/torbenconto/gopwd/internal/crypt/gpg"
+	"github.com/torbenconto/gopwd/internal/crypt"
 	editor "github.com/torbenconto/gopwd/internal/editor_darwin"
+	"github.com/torbenconto/gopwd/internal/history"
 	"github.com/torbenconto/gopwd/internal/io"
 	"github.com/torbenconto/gopwd/internal/util"
 )
@@ -21,16 +21,15 @@ var editCmd = &cobra.Command{
 	ValidArgsFunction: AutocompleteServices,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
-		serviceFile := path.Join(VaultPath, args[0]+".gpg")
+		serviceFile := util.ServicePath(VaultPath, args[0])
 		// Check if service exists
 		if !io.Exists(serviceFile) {
 			return fmt.Errorf("service %s not found", args[0])
 		}
 
-		// Get gpgid
-		gpgid, err := util.ReadGPGID(path.Join(VaultPath, ".gpg-id"))
+		backend, err := util.NewBackend(VaultPath, args[0], crypt.Config{})
 		if err != nil {
-			return fmt.Errorf("failed to read gpg-id: %v", err)
+			return fmt.Errorf("failed to load encryption backend: %v", err)
 		}
 
 		// Read password from file
@@ -40,8 +39,7 @@ var editCmd = &cobra.Command{
 		}
 
 		// Decrypt password
-		gpgmodule := gpg.NewGPG(gpgid, gpg.Config{})
-		password, err = gpgmodule.Decrypt(password)
+		password, err = backend.Decrypt(password)
 		if err != nil {
 			return fmt.Errorf("failed to decrypt password: %v", err)
 		}
@@ -74,12 +72,17 @@ var editCmd = &cobra.Command{
 			return nil
 		}
 
-		encryptedPassword, err := gpgmodule.Encrypt(newPassword)
+		encryptedPassword, err := backend.Encrypt(newPassword)
 		if err != nil {
 			return fmt.Errorf("failed to encrypt password: %v", err)
 		}
 
-		err = io.WriteFile(serviceFile, encryptedPassword)
+		err = history.Save(VaultPath, args[0], serviceFile)
+		if err != nil {
+			return err
+		}
+
+		err = io.WriteFileAtomic(serviceFile, encryptedPassword)
 		if err != nil {
 			return fmt.Errorf("failed to write encrypted password to file: %v", err)
 		}
@@ -90,6 +93,8 @@ var editCmd = &cobra.Command{
 			return fmt.Errorf("failed to remove temporary file: %v", err)
 		}
 
+		commitVault("Edit password for %s using editor", args[0])
+
 		fmt.Println("Password updated successfully")
 
 		return nil
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/exporter"
+	"github.com/torbenconto/gopwd/internal/io"
+	"github.com/torbenconto/gopwd/internal/termio"
+	"github.com/torbenconto/gopwd/internal/util"
+)
+
+var exportCmd = &cobra.Command{
+	Use:               "export [subtree] [flags]",
+	Short:             "Export decrypted entries as JSON, CSV or KeePass XML",
+	Long:              "Decrypt every entry in the vault, or below a subtree, and write them as structured records. Plaintext is only written to a terminal or a file with --plaintext; --recipient encrypts the whole export instead.",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: AutocompleteServices,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var subtree string
+		if len(args) > 0 {
+			subtree = args[0]
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		plaintext, _ := cmd.Flags().GetBool("plaintext")
+		recipients, _ := cmd.Flags().GetStringSlice("recipient")
+		workers, _ := cmd.Flags().GetInt("workers")
+
+		// Check where the export goes before decrypting anything
+		toTerminal := output == "" && termio.IsTerminal(os.Stdout)
+		if len(recipients) == 0 && !plaintext && (output != "" || toTerminal) {
+			return fmt.Errorf("refusing to write a plaintext export to %s, pass --plaintext or encrypt it with --recipient", destination(output))
+		}
+		if len(recipients) > 0 && toTerminal {
+			return fmt.Errorf("refusing to write an encrypted export to a terminal, use --output")
+		}
+
+		if !slices.Contains(exporter.Formats(), format) {
+			return fmt.Errorf("unknown export format %q (available: %v)", format, exporter.Formats())
+		}
+
+		services, err := util.ListServicesUnder(VaultPath, subtree)
+		if err != nil {
+			return fmt.Errorf("failed to list services: %v", err)
+		}
+
+		backends := util.NewBackendCache(VaultPath, crypt.Config{})
+
+		var mu sync.Mutex
+		var records []exporter.Record
+
+		errs := util.ForEachService(services, workers, func(service string) error {
+			backend, err := backends.Get(service)
+			if err != nil {
+				return err
+			}
+
+			file, err := io.ReadFile(util.ServicePath(VaultPath, service))
+			if err != nil {
+				return fmt.Errorf("failed to read file: %v", err)
+			}
+
+			content, err := backend.Decrypt(file)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt password: %v", err)
+			}
+
+			mu.Lock()
+			records = append(records, exporter.NewRecord(service, content))
+			mu.Unlock()
+
+			return nil
+		}, func(done, total int, service string, err error) {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[%d/%d] %s: %v\n", done, total, service, err)
+			}
+		})
+		if len(errs) > 0 {
+			return fmt.Errorf("failed to decrypt %d of %d entries", len(errs), len(services))
+		}
+
+		sort.Slice(records, func(i, j int) bool {
+			return records[i].Service < records[j].Service
+		})
+
+		var buf bytes.Buffer
+		err = exporter.Write(format, &buf, records)
+		if err != nil {
+			return fmt.Errorf("failed to write export: %v", err)
+		}
+		data := buf.Bytes()
+
+		if len(recipients) > 0 {
+			backend, err := crypt.New(util.BackendName(VaultPath), crypt.Config{
+				Recipients: recipients,
+				Keyring:    viper.GetString("keyring"),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to load encryption backend: %v", err)
+			}
+
+			data, err = backend.Encrypt(data)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt export: %v", err)
+			}
+		}
+
+		if output == "" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+
+		// Only the owner may read the export, whether or not it is encrypted
+		err = os.WriteFile(output, data, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to write export: %v", err)
+		}
+
+		fmt.Fprintf(os.Stderr, "Exported %d entries to %s\n", len(records), output)
+
+		return nil
+	},
+}
+
+func destination(output string) string {
+	if output == "" {
+		return "a terminal"
+	}
+	return output
+}
+
+func init() {
+	exportCmd.Flags().StringP("format", "F", "json", "Export format: csv, json or keepass-xml")
+	exportCmd.Flags().StringP("output", "o", "", "File to write the export to instead of stdout")
+	exportCmd.Flags().Bool("plaintext", false, "Allow writing the decrypted export to a terminal or a file")
+	exportCmd.Flags().StringSliceP("recipient", "r", nil, "Encrypt the export for this recipient, can be repeated")
+	exportCmd.Flags().IntP("workers", "w", util.DefaultWorkers, "Number of entries to decrypt at once")
+	rootCmd.AddCommand(exportCmd)
+}
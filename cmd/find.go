@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/torbenconto/gopwd/internal/io"
+	"github.com/torbenconto/gopwd/internal/search"
+	"github.com/torbenconto/gopwd/internal/util"
+)
+
+var findCmd = &cobra.Command{
+	Use:   "find [terms...] [flags]",
+	Short: "Find services by name",
+	Long:  "Print a tree of the services whose path contains any of the terms. With --fuzzy, list the services matching every term as a subsequence, best match first.",
+	Args:  cobra.MinimumNArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fuzzy, _ := cmd.Flags().GetBool("fuzzy")
+
+		services, err := io.ListServices(VaultPath)
+		if err != nil {
+			return fmt.Errorf("failed to list services: %v", err)
+		}
+
+		if fuzzy {
+			results := search.Fuzzy(services, args)
+			if len(results) == 0 {
+				return fmt.Errorf("no services match %v", args)
+			}
+			for _, result := range results {
+				fmt.Println(result.Service)
+			}
+			return nil
+		}
+
+		matches := search.Match(services, args)
+		if len(matches) == 0 {
+			return fmt.Errorf("no services match %v", args)
+		}
+
+		util.PrintServiceTree(matches)
+
+		return nil
+	},
+}
+
+func init() {
+	findCmd.Flags().BoolP("fuzzy", "z", false, "Rank services by fuzzy match instead of printing a tree of substring matches")
+	rootCmd.AddCommand(findCmd)
+}
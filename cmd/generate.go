@@ -2,12 +2,15 @@ package cmd
 
 import (
 	"fmt"
-	"path"
+	"time"
 
-	"github.com/atotto/clipboard"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
-	"github.com/torbenconto/gopwd/internal/crypt/gpg"
+	"github.com/torbenconto/gopwd/internal/breach"
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/io"
+	"github.com/torbenconto/gopwd/internal/policy"
 	"github.com/torbenconto/gopwd/internal/pwgen"
 	"github.com/torbenconto/gopwd/internal/util"
 )
@@ -20,72 +23,233 @@ var generateCmd = &cobra.Command{
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		service := args[0]
-		servicePath := path.Join(VaultPath, service) + ".gpg"
-
-		length, _ := cmd.Flags().GetInt("length")
-		symbols, _ := cmd.Flags().GetBool("symbols")
-		numbers, _ := cmd.Flags().GetBool("numbers")
-		uppercase, _ := cmd.Flags().GetBool("uppercase")
-		lowercase, _ := cmd.Flags().GetBool("lowercase")
-		human, _ := cmd.Flags().GetBool("memorable")
+		servicePath := util.ServicePath(VaultPath, service)
+
 		copyFlag, _ := cmd.Flags().GetBool("copy")
+		inPlaceFlag, _ := cmd.Flags().GetBool("in-place")
+		policyFlag, _ := cmd.Flags().GetString("policy")
 
-		// Generate password
-		generator := pwgen.NewPasswordGenerator(pwgen.PasswordGeneratorConfig{
-			Length:    length,
-			Humanized: human,
-			Symbols:   symbols,
-			Numbers:   numbers,
-			Lowercase: lowercase,
-			Uppercase: uppercase,
-		})
+		// Options given on the command line take precedence over the policy of the service
+		config := pwgen.DefaultConfig()
+		applyGenerateFlags(cmd, &config, false)
 
-		password, err := generator.Generate()
+		policyName, err := policy.Resolve(service, policyFlag)
 		if err != nil {
-			return fmt.Errorf("failed to generate password: %v", err)
+			return err
+		}
+		if policyName != "" {
+			err = policy.Apply(policyName, &config)
+			if err != nil {
+				return err
+			}
+			applyGenerateFlags(cmd, &config, true)
+			fmt.Printf("Using policy %s\n", policyName)
 		}
 
-		// Get the GPG ID from the .gpg-id file
-		gpgID, err := util.ReadGPGID(path.Join(VaultPath, ".gpg-id"))
-		if err != nil {
-			return fmt.Errorf("failed to read GPG ID from .gpg-id file, error: %v", err)
+		if inPlaceFlag && !io.Exists(servicePath) {
+			return fmt.Errorf("service %s not found", service)
 		}
 
-		// Encrypt the password and write it to the .gpg file
+		// Generate password
+		generator := pwgen.NewPasswordGenerator(config)
 
-		GPG := gpg.NewGPG(gpgID, gpg.Config{})
-		encryptedPassword, err := GPG.Encrypt([]byte(password))
+		entropy, err := generator.Entropy()
 		if err != nil {
-			return fmt.Errorf("failed to encrypt password for service: %s, error: %v", service, err)
+			return err
 		}
 
-		err = util.CreateStructureAndClean(service, VaultPath, servicePath, encryptedPassword)
+		password, err := generateUnbreached(cmd, generator)
 		if err != nil {
-			return fmt.Errorf("failed to create structure and clean up, error: %v", err)
+			return err
+		}
+
+		var age string
+		if inPlaceFlag {
+			setAt, err := util.ReplacePassword(VaultPath, service, password, crypt.Config{})
+			if err != nil {
+				return fmt.Errorf("failed to replace password for service: %s, error: %v", service, err)
+			}
+
+			commitVault("Rotate generated password for %s", service)
+			age = passwordAge(time.Since(setAt))
+		} else {
+			backend, err := util.NewBackend(VaultPath, service, crypt.Config{})
+			if err != nil {
+				return fmt.Errorf("failed to load encryption backend, error: %v", err)
+			}
+
+			// Encrypt the password and write it to the .gpg file
+			encryptedPassword, err := backend.Encrypt([]byte(password))
+			if err != nil {
+				return fmt.Errorf("failed to encrypt password for service: %s, error: %v", service, err)
+			}
+
+			err = util.CreateStructureAndClean(service, VaultPath, servicePath, encryptedPassword)
+			if err != nil {
+				return fmt.Errorf("failed to create structure and clean up, error: %v", err)
+			}
+
+			commitVault("Add generated password for %s", service)
 		}
 
 		if copyFlag {
-			err = clipboard.WriteAll(password)
+			err = copySecret(cmd, password, fmt.Sprintf("Copied password for %s to clipboard (%.0f bits of entropy)", service, entropy))
 			if err != nil {
 				return fmt.Errorf("failed to copy password to clipboard, error: %v", err)
 			}
-			fmt.Printf("Copied password for %s to clipboard\n", service)
-			return nil
+		} else if inPlaceFlag {
+			fmt.Printf("Password for %s replaced successfully (%.0f bits of entropy)\n", service, entropy)
+		} else {
+			fmt.Printf("Password for %s inserted successfully (%.0f bits of entropy)\n", service, entropy)
 		}
 
-		fmt.Printf("Password for %s inserted successfully\n", service)
+		if inPlaceFlag {
+			fmt.Printf("The old password was %s\n", age)
+		}
 
 		return nil
 	},
 }
 
+// passwordAge describes how long ago a password was set, in days.
+func passwordAge(age time.Duration) string {
+	days := int(age.Hours() / 24)
+	switch days {
+	case 0:
+		return "set today"
+	case 1:
+		return "1 day old"
+	default:
+		return fmt.Sprintf("%d days old", days)
+	}
+}
+
+// applyGenerateFlags copies the generator flags of cmd into config, or only those given on the
+// command line if onlyChanged is set.
+func applyGenerateFlags(cmd *cobra.Command, config *pwgen.PasswordGeneratorConfig, onlyChanged bool) {
+	flags := cmd.Flags()
+	set := func(name string) bool {
+		return !onlyChanged || flags.Changed(name)
+	}
+
+	if set("length") {
+		config.Length, _ = flags.GetInt("length")
+	}
+	if set("symbols") {
+		config.Symbols, _ = flags.GetBool("symbols")
+	}
+	if set("numbers") {
+		config.Numbers, _ = flags.GetBool("numbers")
+	}
+	if set("uppercase") {
+		config.Uppercase, _ = flags.GetBool("uppercase")
+	}
+	if set("lowercase") {
+		config.Lowercase, _ = flags.GetBool("lowercase")
+	}
+	if set("memorable") {
+		config.Humanized, _ = flags.GetBool("memorable")
+	}
+	if set("words") {
+		config.Words, _ = flags.GetInt("words")
+	}
+	if flags.Changed("sep") {
+		separator, _ := flags.GetString("sep")
+		config.Separator = &separator
+	}
+	if set("capitalize") {
+		config.Capitalization, _ = flags.GetString("capitalize")
+	}
+	if set("wordlist") {
+		config.Wordlist, _ = flags.GetString("wordlist")
+	}
+	if set("min-symbols") {
+		config.MinSymbols, _ = flags.GetInt("min-symbols")
+	}
+	if set("min-numbers") {
+		config.MinNumbers, _ = flags.GetInt("min-numbers")
+	}
+	if set("min-lowercase") {
+		config.MinLowercase, _ = flags.GetInt("min-lowercase")
+	}
+	if set("min-uppercase") {
+		config.MinUppercase, _ = flags.GetInt("min-uppercase")
+	}
+	if set("exclude") {
+		config.Exclude, _ = flags.GetString("exclude")
+	}
+	if set("symbol-set") {
+		config.AllowedSymbols, _ = flags.GetString("symbol-set")
+	}
+	if set("pattern") {
+		config.Pattern, _ = flags.GetString("pattern")
+	}
+}
+
+// maxBreachedAttempts is how many breached passwords generate throws away before giving up.
+const maxBreachedAttempts = 10
+
+// generateUnbreached generates a password, refusing those found in the breach database given by
+// --breach-db or breachDB in the config.
+func generateUnbreached(cmd *cobra.Command, generator *pwgen.PasswordGenerator) (string, error) {
+	breachDB, _ := cmd.Flags().GetString("breach-db")
+	if !cmd.Flags().Changed("breach-db") {
+		breachDB = viper.GetString("breachDB")
+	}
+
+	var db *breach.DB
+	if breachDB != "" {
+		var err error
+		db, err = breach.Open(breachDB)
+		if err != nil {
+			return "", err
+		}
+		defer db.Close()
+	}
+
+	for attempt := 0; attempt < maxBreachedAttempts; attempt++ {
+		password, err := generator.Generate()
+		if err != nil {
+			return "", fmt.Errorf("failed to generate password: %v", err)
+		}
+		if db == nil {
+			return password, nil
+		}
+
+		count, err := db.Count(password)
+		if err != nil {
+			return "", fmt.Errorf("failed to search breach database: %v", err)
+		}
+		if count == 0 {
+			return password, nil
+		}
+	}
+
+	return "", fmt.Errorf("refusing to use generated password: %d attempts in a row were found in the breach database, use a longer password", maxBreachedAttempts)
+}
+
 func init() {
 	generateCmd.Flags().IntP("length", "l", 16, "Length of the generated password")
 	generateCmd.Flags().BoolP("symbols", "s", true, "Include symbols in the generated password")
 	generateCmd.Flags().BoolP("numbers", "n", true, "Include numbers in the generated password")
 	generateCmd.Flags().BoolP("uppercase", "u", true, "Include uppercase letters in the generated password")
 	generateCmd.Flags().BoolP("lowercase", "L", true, "Include lowercase letters in the generated password")
-	generateCmd.Flags().BoolP("memorable", "m", false, "Include words in the generated password")
+	generateCmd.Flags().Int("min-symbols", 0, "Least number of symbols in the generated password")
+	generateCmd.Flags().Int("min-numbers", 0, "Least number of digits in the generated password")
+	generateCmd.Flags().Int("min-lowercase", 0, "Least number of lowercase letters in the generated password")
+	generateCmd.Flags().Int("min-uppercase", 0, "Least number of uppercase letters in the generated password")
+	generateCmd.Flags().StringP("exclude", "x", "", "Characters never to use, such as 0O1lI")
+	generateCmd.Flags().String("symbol-set", "", "Symbols to choose from instead of all ASCII punctuation")
+	generateCmd.Flags().StringP("pattern", "p", "", "Generate a password in a fixed shape, such as Cvccvc99! or X{4}-X{4}-X{4}")
+	generateCmd.Flags().BoolP("memorable", "m", false, "Generate a passphrase of whole words (5 unless --words is given)")
+	generateCmd.Flags().Int("words", 0, "Generate a passphrase of this many words")
+	generateCmd.Flags().String("sep", pwgen.DefaultSeparator, "Separator between the words of a passphrase")
+	generateCmd.Flags().String("capitalize", pwgen.CapitalizeNone, "Capitalisation of passphrase words: none, first, all or random")
+	generateCmd.Flags().String("wordlist", pwgen.WordlistBIP39, "Passphrase wordlist: bip39, eff (the EFF long list) or the path of a wordlist file")
 	generateCmd.Flags().BoolP("copy", "c", false, "Copy the generated password to the clipboard")
+	generateCmd.Flags().BoolP("in-place", "i", false, "Replace only the password of an existing entry, keeping its other lines")
+	generateCmd.Flags().String("policy", "", "Generate with this policy from the config instead of the one matching the service")
+	generateCmd.Flags().String("breach-db", "", "Sorted Pwned Passwords SHA-1 file; generated passwords found in it are refused (default from breachDB in the config)")
+	addClipTimeoutFlag(generateCmd)
 	rootCmd.AddCommand(generateCmd)
 }
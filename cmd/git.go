@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/torbenconto/gopwd/internal/git"
+)
+
+var gitCmd = &cobra.Command{
+	Use:   "git",
+	Short: "Command palette for keeping the vault in git",
+
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var gitInitCmd = &cobra.Command{
+	Use:   "init [flags]",
+	Short: "Turn the vault into a git repository",
+	Args:  cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		remote, _ := cmd.Flags().GetString("remote")
+
+		g := git.NewGit(VaultPath)
+		err := g.Init()
+		if err != nil {
+			return fmt.Errorf("failed to initialize git: %v", err)
+		}
+
+		if remote != "" {
+			err = g.Run("remote", "add", "origin", remote)
+			if err != nil {
+				return fmt.Errorf("failed to add remote: %v", err)
+			}
+		}
+
+		fmt.Println("Git enabled, changes to the vault are now committed automatically")
+
+		return nil
+	},
+}
+
+var gitPushCmd = &cobra.Command{
+	Use:                "push [git push args]",
+	Short:              "Push the vault to its remote",
+	DisableFlagParsing: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			args = []string{"-u", "origin", "HEAD"}
+		}
+		return runGit(append([]string{"push"}, args...)...)
+	},
+}
+
+var gitPullCmd = &cobra.Command{
+	Use:                "pull [git pull args]",
+	Short:              "Pull changes to the vault from its remote",
+	DisableFlagParsing: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			args = []string{"--rebase"}
+		}
+		return runGit(append([]string{"pull"}, args...)...)
+	},
+}
+
+var gitLogCmd = &cobra.Command{
+	Use:                "log [git log args]",
+	Short:              "Show the history of the vault",
+	DisableFlagParsing: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGit(append([]string{"log"}, args...)...)
+	},
+}
+
+func runGit(args ...string) error {
+	g := git.NewGit(VaultPath)
+	if !g.Enabled() {
+		return fmt.Errorf("git is not enabled for this vault. Run 'gopwd git init' first")
+	}
+
+	return g.Run(args...)
+}
+
+// commitVault commits the changes made by a command when git is enabled. The change itself
+// already succeeded, so a failed commit is only reported.
+func commitVault(format string, a ...any) {
+	err := git.AutoCommit(VaultPath, fmt.Sprintf(format, a...))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to commit change to git: %v\n", err)
+	}
+}
+
+func init() {
+	gitInitCmd.Flags().StringP("remote", "r", "", "URL of the remote to push to")
+	gitCmd.AddCommand(gitInitCmd)
+	gitCmd.AddCommand(gitPushCmd)
+	gitCmd.AddCommand(gitPullCmd)
+	gitCmd.AddCommand(gitLogCmd)
+	rootCmd.AddCommand(gitCmd)
+}
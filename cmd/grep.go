@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/entry"
+	"github.com/torbenconto/gopwd/internal/io"
+	"github.com/torbenconto/gopwd/internal/util"
+)
+
+var grepCmd = &cobra.Command{
+	Use:               "grep [pattern] [subtree] [flags]",
+	Short:             "Search the contents of entries",
+	Long:              "Decrypt every entry in the vault, or below a subtree, and print the lines matching pattern. Lines that look like passwords are masked unless --unmask is given.",
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: AutocompleteServices,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pattern := args[0]
+		var subtree string
+		if len(args) > 1 {
+			subtree = args[1]
+		}
+
+		ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+		extended, _ := cmd.Flags().GetBool("extended-regexp")
+		unmask, _ := cmd.Flags().GetBool("unmask")
+		workers, _ := cmd.Flags().GetInt("workers")
+
+		// Match the pattern literally unless -E is given
+		if !extended {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+		if ignoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %v", err)
+		}
+
+		services, err := util.ListServicesUnder(VaultPath, subtree)
+		if err != nil {
+			return fmt.Errorf("failed to list services: %v", err)
+		}
+
+		backends := util.NewBackendCache(VaultPath, crypt.Config{})
+
+		var mu sync.Mutex
+		matches := map[string][]string{}
+
+		errs := util.ForEachService(services, workers, func(service string) error {
+			backend, err := backends.Get(service)
+			if err != nil {
+				return err
+			}
+
+			file, err := io.ReadFile(util.ServicePath(VaultPath, service))
+			if err != nil {
+				return fmt.Errorf("failed to read file: %v", err)
+			}
+
+			content, err := backend.Decrypt(file)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt password: %v", err)
+			}
+
+			parsed := entry.Parse(content)
+
+			var lines []string
+			for i, line := range parsed.Lines() {
+				if !re.MatchString(line) {
+					continue
+				}
+				if !unmask && parsed.IsSecretLine(i) {
+					line = parsed.MaskLine(i)
+				}
+				lines = append(lines, line)
+			}
+
+			if len(lines) > 0 {
+				mu.Lock()
+				matches[service] = lines
+				mu.Unlock()
+			}
+
+			return nil
+		}, func(done, total int, service string, err error) {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "\r[%d/%d] %s: %v\n", done, total, service, err)
+				return
+			}
+			fmt.Fprintf(os.Stderr, "\r[%d/%d] searching", done, total)
+			if done == total {
+				fmt.Fprintln(os.Stderr)
+			}
+		})
+
+		// Print sorted alphabetically by service, whichever worker finished first
+		found := make([]string, 0, len(matches))
+		for service := range matches {
+			found = append(found, service)
+		}
+		sort.Strings(found)
+
+		for _, service := range found {
+			fmt.Printf("%s:\n", service)
+			for _, line := range matches[service] {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+
+		if len(errs) > 0 {
+			return fmt.Errorf("failed to search %d of %d entries", len(errs), len(services))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	grepCmd.Flags().BoolP("ignore-case", "i", false, "Ignore case when matching")
+	grepCmd.Flags().BoolP("extended-regexp", "E", false, "Interpret the pattern as a regular expression")
+	grepCmd.Flags().BoolP("unmask", "u", false, "Print matching lines that look like passwords as they are")
+	grepCmd.Flags().IntP("workers", "w", util.DefaultWorkers, "Number of entries to decrypt at once")
+	rootCmd.AddCommand(grepCmd)
+}
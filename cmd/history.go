@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/history"
+	"github.com/torbenconto/gopwd/internal/io"
+	"github.com/torbenconto/gopwd/internal/util"
+)
+
+var historyCmd = &cobra.Command{
+	Use:               "history [service] [flags]",
+	Short:             "List earlier versions of a password",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: AutocompleteServices,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		service := args[0]
+
+		versions, err := history.List(VaultPath, service)
+		if err != nil {
+			return fmt.Errorf("failed to read history: %v", err)
+		}
+
+		if len(versions) == 0 {
+			fmt.Printf("No earlier versions of %s\n", service)
+			return nil
+		}
+
+		for _, version := range versions {
+			fmt.Printf("%3d  %s\n", version.Number, version.Time.Format(time.DateTime))
+		}
+
+		if info, err := io.Stat(util.ServicePath(VaultPath, service)); err == nil {
+			fmt.Printf("%3s  %s\n", "*", info.ModTime().Format(time.DateTime))
+		}
+
+		return nil
+	},
+}
+
+var revertCmd = &cobra.Command{
+	Use:               "revert [service] [version] [flags]",
+	Short:             "Restore an earlier version of a password",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: AutocompleteServices,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		service := args[0]
+		servicePath := util.ServicePath(VaultPath, service)
+
+		number, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %s", args[1])
+		}
+
+		version, err := history.Get(VaultPath, service, number)
+		if err != nil {
+			return err
+		}
+
+		file, err := io.ReadFile(version.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %v", err)
+		}
+
+		backend, err := util.NewBackend(VaultPath, service, crypt.Config{})
+		if err != nil {
+			return fmt.Errorf("failed to load encryption backend: %v", err)
+		}
+
+		// Re-encrypt so the restored version uses the current recipients
+		password, err := backend.Decrypt(file)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt password: %v", err)
+		}
+
+		encryptedPassword, err := backend.Encrypt(password)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt password: %v", err)
+		}
+
+		err = util.CreateStructureAndClean(service, VaultPath, servicePath, encryptedPassword)
+		if err != nil {
+			return fmt.Errorf("failed to create structure and clean up, error: %v", err)
+		}
+
+		commitVault("Revert %s to version %d", service, number)
+
+		fmt.Printf("Reverted %s to version %d\n", service, number)
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(revertCmd)
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/importer"
+	"github.com/torbenconto/gopwd/internal/util"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import entries from other password managers",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var importPassCmd = &cobra.Command{
+	Use:   "pass [dir] [flags]",
+	Short: "Import a pass (password-store) directory",
+	Long:  "Import the entries of a pass store into the vault. Entries encrypted for the same recipients as their place in the vault are copied as they are, the rest are re-encrypted. Nested and multi-key .gpg-id files are honoured and .git is ignored.",
+	Args:  cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := args[0]
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		strategy, _ := cmd.Flags().GetString("on-conflict")
+		workers, _ := cmd.Flags().GetInt("workers")
+
+		backends := util.NewBackendCache(VaultPath, crypt.Config{})
+
+		entries, err := importer.PlanPass(VaultPath, dir, importer.Strategy(strategy), backends)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Println("No entries to import")
+			return nil
+		}
+
+		items := make([]importer.Item, len(entries))
+		for i, e := range entries {
+			items[i] = e.Item
+		}
+		imported := printImportPlan(items)
+
+		if dryRun || imported == 0 {
+			fmt.Printf("%d of %d entries would be imported\n", imported, len(entries))
+			return nil
+		}
+
+		errs, err := importer.ImportPass(VaultPath, entries, backends, workers, importProgress)
+		if err != nil {
+			return err
+		}
+
+		if len(errs) < imported {
+			commitVault("Import %d entries from pass", imported-len(errs))
+		}
+
+		if len(errs) > 0 {
+			return fmt.Errorf("failed to import %d of %d entries", len(errs), imported)
+		}
+
+		fmt.Printf("Imported %d entries\n", imported)
+
+		return nil
+	},
+}
+
+// newImportFormatCmd returns the import subcommand of an export format registered with the importer.
+func newImportFormatCmd(format string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   format + " [file] [flags]",
+		Short: "Import " + importer.Describe(format),
+		Long:  "Import the entries of " + importer.Describe(format) + ". Folders and collections become directories of the vault.",
+		Args:  cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			strategy, _ := cmd.Flags().GetString("on-conflict")
+			workers, _ := cmd.Flags().GetInt("workers")
+			prefix, _ := cmd.Flags().GetString("dir")
+
+			file, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open export: %v", err)
+			}
+			defer file.Close()
+
+			records, err := importer.Parse(format, file)
+			if err != nil {
+				return err
+			}
+
+			items, err := importer.PlanRecords(VaultPath, records, prefix, importer.Strategy(strategy))
+			if err != nil {
+				return err
+			}
+			if len(items) == 0 {
+				fmt.Println("No entries to import")
+				return nil
+			}
+
+			plan := make([]importer.Item, len(items))
+			for i, item := range items {
+				plan[i] = item.Item
+			}
+			imported := printImportPlan(plan)
+
+			if dryRun || imported == 0 {
+				fmt.Printf("%d of %d entries would be imported\n", imported, len(items))
+				return nil
+			}
+
+			backends := util.NewBackendCache(VaultPath, crypt.Config{})
+			errs := importer.ImportRecords(VaultPath, items, backends, workers, importProgress)
+
+			if len(errs) < imported {
+				commitVault("Import %d entries from %s", imported-len(errs), format)
+			}
+
+			if len(errs) > 0 {
+				return fmt.Errorf("failed to import %d of %d entries", len(errs), imported)
+			}
+
+			fmt.Printf("Imported %d entries\n", imported)
+
+			return nil
+		},
+	}
+
+	addImportFlags(cmd)
+	cmd.Flags().StringP("dir", "d", "", "Directory of the vault to import into")
+
+	return cmd
+}
+
+// printImportPlan prints what an import does with each entry and returns how many are imported.
+func printImportPlan(items []importer.Item) int {
+	imported := 0
+	for _, item := range items {
+		switch {
+		case item.Action == importer.Ignore:
+			fmt.Printf("skip %s (already exists)\n", item.Service)
+		case item.Target != item.Service:
+			fmt.Printf("%s %s -> %s (already exists)\n", item.Action, item.Service, item.Target)
+		case item.Conflict:
+			fmt.Printf("%s %s (overwrite)\n", item.Action, item.Service)
+		default:
+			fmt.Printf("%s %s\n", item.Action, item.Service)
+		}
+		if item.Action != importer.Ignore {
+			imported++
+		}
+	}
+	return imported
+}
+
+func importProgress(done, total int, service string, err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[%d/%d] %s: %v\n", done, total, service, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[%d/%d] %s\n", done, total, service)
+}
+
+func addImportFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("dry-run", "n", false, "Show what would be imported without changing the vault")
+	cmd.Flags().String("on-conflict", string(importer.Skip), "What to do with entries that already exist: skip, overwrite or rename")
+	cmd.Flags().IntP("workers", "w", util.DefaultWorkers, "Number of entries to import at once")
+}
+
+func init() {
+	addImportFlags(importPassCmd)
+	importCmd.AddCommand(importPassCmd)
+	for _, format := range importer.Formats() {
+		importCmd.AddCommand(newImportFormatCmd(format))
+	}
+	rootCmd.AddCommand(importCmd)
+}
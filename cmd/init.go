@@ -2,20 +2,75 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/crypt/age"
 	"github.com/torbenconto/gopwd/internal/io"
 	util2 "github.com/torbenconto/gopwd/internal/util"
 )
 
 var initCmd = &cobra.Command{
-	Use:   "init [gpg-id] [flags]",
+	Use:   "init [gpg-id...|age-recipient...] [flags]",
 	Short: "Initialize gopwd vault at a path",
-	Args:  cobra.ExactArgs(1),
+	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		vaultPath, _ := cmd.Flags().GetString("path")
+		backend, _ := cmd.Flags().GetString("backend")
+		subdir, _ := cmd.Flags().GetString("subdir")
+
+		// scoped recipients go into an existing vault, so use its path and backend
+		if subdir != "" {
+			if !cmd.Flags().Changed("path") {
+				vaultPath = viper.GetString("vaultPath")
+			}
+			if !io.Exists(vaultPath) {
+				return fmt.Errorf("gopwd vault not initialized. Run 'gopwd init' to initialize")
+			}
+			backend = util2.BackendName(vaultPath)
+		}
+
+		// check the backend and its recipients before touching the filesystem
+		format, err := crypt.FormatOf(backend)
+		if err != nil {
+			return err
+		}
+		if backend == age.Name {
+			for _, recipient := range args {
+				if _, err := age.ParseRecipient(recipient); err != nil {
+					return err
+				}
+			}
+		}
+
+		recipients := []byte(strings.Join(args, "\n") + "\n")
+
+		if subdir != "" {
+			dir := filepath.Join(vaultPath, filepath.Clean(subdir))
+			if rel, err := filepath.Rel(vaultPath, dir); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+				return fmt.Errorf("subdir %s is not inside the vault", subdir)
+			}
+
+			err = os.MkdirAll(dir, 0755)
+			if err != nil {
+				return err
+			}
+
+			// write one recipient per line to the scoped recipients file
+			err = io.WriteFile(filepath.Join(dir, format.RecipientsFile), recipients)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Entries under %s will be encrypted for %s\n", subdir, strings.Join(args, ", "))
+			return nil
+		}
 
 		// check if vaultPath exists
 		// if not, create it
@@ -28,19 +83,19 @@ var initCmd = &cobra.Command{
 			return fmt.Errorf("vault already exists at %s", vaultPath)
 		}
 
-		err := io.WriteFile(path.Join(GopwdPath, ".gopwd.yaml"), []byte(fmt.Sprintf("vaultPath: %s", vaultPath)))
+		err = io.WriteFile(path.Join(GopwdPath, ".gopwd.yaml"), []byte(fmt.Sprintf("vaultPath: %s\nbackend: %s\n", vaultPath, backend)))
 		if err != nil {
 			return err
 		}
 
-		// create .gpg-id file in vaultPath
-		_, err = io.CreateFile(path.Join(vaultPath, ".gpg-id"))
+		// create recipients file (.gpg-id or .age-recipients) in vaultPath
+		_, err = io.CreateFile(path.Join(vaultPath, format.RecipientsFile))
 		if err != nil {
 			return err
 		}
 
-		// write gpg-id to .gpg-id file
-		err = io.WriteFile(path.Join(vaultPath, ".gpg-id"), []byte(args[0]))
+		// write one recipient per line to the recipients file
+		err = io.WriteFile(path.Join(vaultPath, format.RecipientsFile), recipients)
 		if err != nil {
 			return err
 		}
@@ -72,5 +127,7 @@ func init() {
 	}
 
 	initCmd.Flags().StringP("path", "p", path.Join(GopwdPath, "vault"), "path to gopwd vault")
+	initCmd.Flags().StringP("backend", "b", crypt.DefaultBackend, "encryption backend used by the vault")
+	initCmd.Flags().StringP("subdir", "s", "", "write recipients for a subdirectory of an existing vault")
 	rootCmd.AddCommand(initCmd)
 }
@@ -2,12 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"path"
 
-	"github.com/atotto/clipboard"
 	"github.com/spf13/cobra"
 
-	"github.com/torbenconto/gopwd/internal/crypt/gpg"
+	"github.com/torbenconto/gopwd/internal/crypt"
 	"github.com/torbenconto/gopwd/internal/termio"
 	"github.com/torbenconto/gopwd/internal/util"
 )
@@ -20,7 +18,7 @@ var insertCmd = &cobra.Command{
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		service := args[0]
-		servicePath := path.Join(VaultPath, service) + ".gpg"
+		servicePath := util.ServicePath(VaultPath, service)
 
 		// Flags
 		copyFlag, _ := cmd.Flags().GetBool("copy")
@@ -39,28 +37,29 @@ var insertCmd = &cobra.Command{
 			return fmt.Errorf("failed to read password: %v", err)
 		}
 
-		// Get the GPG ID from the .gpg-id file
-		gpgID, err := util.ReadGPGID(path.Join(VaultPath, ".gpg-id"))
+		backend, err := util.NewBackend(VaultPath, service, crypt.Config{})
 		if err != nil {
-			return fmt.Errorf("failed to read GPG ID from .gpg-id file, error: %v", err)
+			return fmt.Errorf("failed to load encryption backend, error: %v", err)
 		}
 
 		// Encrypt the password and write it to the .gpg file
-
-		GPG := gpg.NewGPG(gpgID, gpg.Config{})
-		encryptedPassword, err := GPG.Encrypt([]byte(password))
+		encryptedPassword, err := backend.Encrypt([]byte(password))
 		if err != nil {
 			return fmt.Errorf("failed to encrypt password for service: %s, error: %v", service, err)
 		}
 
 		err = util.CreateStructureAndClean(service, VaultPath, servicePath, encryptedPassword)
+		if err != nil {
+			return fmt.Errorf("failed to create structure and clean up, error: %v", err)
+		}
+
+		commitVault("Add given password for %s", service)
 
 		if copyFlag {
-			err = clipboard.WriteAll(password)
+			err = copySecret(cmd, password, fmt.Sprintf("Copied password for %s to clipboard", service))
 			if err != nil {
 				return fmt.Errorf("failed to copy password to clipboard, error: %v", err)
 			}
-			fmt.Printf("Copied password for %s to clipboard\n", service)
 			return nil
 		}
 
@@ -73,5 +72,6 @@ var insertCmd = &cobra.Command{
 func init() {
 	insertCmd.Flags().BoolP("copy", "c", false, "Copy the password to the clipboard")
 	insertCmd.Flags().BoolP("multiline", "m", false, "Input a multiline password")
+	addClipTimeoutFlag(insertCmd)
 	rootCmd.AddCommand(insertCmd)
 }
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/entry"
+	"github.com/torbenconto/gopwd/internal/history"
+	"github.com/torbenconto/gopwd/internal/io"
+	"github.com/torbenconto/gopwd/internal/otp"
+	"github.com/torbenconto/gopwd/internal/qr"
+	"github.com/torbenconto/gopwd/internal/termio"
+	"github.com/torbenconto/gopwd/internal/util"
+)
+
+var otpCmd = &cobra.Command{
+	Use:               "otp [service] [flags]",
+	Short:             "Show a one-time code for a service",
+	Long:              "Show the one-time code for the otpauth:// line of a service. HOTP counters are incremented and saved.",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: AutocompleteServices,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		service := args[0]
+		copyFlag, _ := cmd.Flags().GetBool("copy")
+
+		key, code, err := util.NextOTP(VaultPath, service, crypt.Config{})
+		if err != nil {
+			return err
+		}
+
+		if key.Type == otp.HOTP {
+			commitVault("Increment HOTP counter for %s", service)
+		}
+
+		if copyFlag {
+			err = copySecret(cmd, code, fmt.Sprintf("Copied one-time code for %s to clipboard", service))
+			if err != nil {
+				return fmt.Errorf("failed to copy code to clipboard: %w", err)
+			}
+			return nil
+		}
+
+		if key.Type == otp.TOTP {
+			fmt.Printf("%s (%ds remaining)\n", code, int(key.Remaining(time.Now()).Seconds()))
+		} else {
+			fmt.Println(code)
+		}
+
+		return nil
+	},
+}
+
+var otpInsertCmd = &cobra.Command{
+	Use:               "insert [service] [otpauth-uri|secret] [flags]",
+	Short:             "Add a one-time password seed to a service",
+	Long:              "Add an otpauth:// URI, or a base32 secret for a default TOTP key, to a service. An existing otpauth:// line is replaced. Without a value, the value is read from the terminal.",
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: AutocompleteServices,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		service := args[0]
+		qrFlag, _ := cmd.Flags().GetBool("qr")
+
+		var value string
+		var err error
+		if len(args) > 1 {
+			value = args[1]
+		} else {
+			value, err = termio.ReadPassphrase("Enter otpauth URI or secret: ")
+			if err != nil {
+				return fmt.Errorf("failed to read secret: %v", err)
+			}
+		}
+
+		var key *otp.Key
+		if strings.HasPrefix(value, otp.Scheme) {
+			key, err = otp.ParseURI(value)
+		} else {
+			key, err = otp.NewTOTP(service, value)
+		}
+		if err != nil {
+			return err
+		}
+
+		servicePath := util.ServicePath(VaultPath, service)
+
+		// Add the seed to an existing entry, or create an entry holding only the seed
+		if io.Exists(servicePath) {
+			content, backend, err := util.DecryptService(VaultPath, service, crypt.Config{})
+			if err != nil {
+				return err
+			}
+
+			parsed := entry.Parse(content)
+			line, ok := otp.FindLine(parsed.Lines())
+			if !ok {
+				line = len(parsed.Lines())
+			}
+			parsed.SetLine(line, key.URI())
+
+			err = history.Save(VaultPath, service, servicePath)
+			if err != nil {
+				return fmt.Errorf("failed to save previous version: %v", err)
+			}
+
+			err = util.EncryptService(VaultPath, service, backend, parsed.Bytes())
+			if err != nil {
+				return err
+			}
+		} else {
+			backend, err := util.NewBackend(VaultPath, service, crypt.Config{})
+			if err != nil {
+				return fmt.Errorf("failed to load encryption backend: %v", err)
+			}
+
+			encrypted, err := backend.Encrypt([]byte(key.URI()))
+			if err != nil {
+				return fmt.Errorf("failed to encrypt password for service: %s, error: %v", service, err)
+			}
+
+			err = util.CreateStructureAndClean(service, VaultPath, servicePath, encrypted)
+			if err != nil {
+				return fmt.Errorf("failed to create structure and clean up, error: %v", err)
+			}
+		}
+
+		commitVault("Add OTP secret for %s", service)
+
+		if qrFlag {
+			qr.Generate(key.URI(), qr.M, os.Stdout)
+		}
+
+		fmt.Printf("One-time password seed for %s inserted successfully\n", service)
+
+		return nil
+	},
+}
+
+func init() {
+	otpCmd.Flags().BoolP("copy", "c", false, "Copy the code to the clipboard")
+	addClipTimeoutFlag(otpCmd)
+	otpInsertCmd.Flags().BoolP("qr", "q", false, "Show a QR code of the otpauth URI for other authenticator apps")
+	otpCmd.AddCommand(otpInsertCmd)
+	rootCmd.AddCommand(otpCmd)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/io"
+	"github.com/torbenconto/gopwd/internal/util"
+)
+
+var reencryptCmd = &cobra.Command{
+	Use:               "reencrypt [subtree] [flags]",
+	Short:             "Re-encrypt entries for their current recipients",
+	Long:              "Re-encrypt every entry in the vault, or below a subtree, for the recipients in its nearest .gpg-id. Run this after adding or removing recipients.",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: AutocompleteServices,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var subtree string
+		if len(args) > 0 {
+			subtree = args[0]
+		}
+
+		workers, _ := cmd.Flags().GetInt("workers")
+
+		services, err := util.ListServicesUnder(VaultPath, subtree)
+		if err != nil {
+			return fmt.Errorf("failed to list services: %v", err)
+		}
+		if len(services) == 0 {
+			fmt.Println("No entries to re-encrypt")
+			return nil
+		}
+
+		// Entries sharing a recipients file share a backend
+		backends := util.NewBackendCache(VaultPath, crypt.Config{})
+
+		errs := util.ForEachService(services, workers, func(service string) error {
+			backend, err := backends.Get(service)
+			if err != nil {
+				return err
+			}
+
+			servicePath := util.ServicePath(VaultPath, service)
+			file, err := io.ReadFile(servicePath)
+			if err != nil {
+				return fmt.Errorf("failed to read file: %v", err)
+			}
+
+			password, err := backend.Decrypt(file)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt password: %v", err)
+			}
+
+			encrypted, err := backend.Encrypt(password)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt password: %v", err)
+			}
+
+			// Replace the entry in one step so a failure never leaves it half-written
+			return io.WriteFileAtomic(servicePath, encrypted)
+		}, func(done, total int, service string, err error) {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[%d/%d] %s: %v\n", done, total, service, err)
+				return
+			}
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s\n", done, total, service)
+		})
+
+		if len(errs) > 0 {
+			return fmt.Errorf("failed to re-encrypt %d of %d entries", len(errs), len(services))
+		}
+
+		if subtree != "" {
+			commitVault("Re-encrypt %s", subtree)
+		} else {
+			commitVault("Re-encrypt vault")
+		}
+
+		fmt.Printf("Re-encrypted %d entries\n", len(services))
+
+		return nil
+	},
+}
+
+func init() {
+	reencryptCmd.Flags().IntP("workers", "w", util.DefaultWorkers, "Number of entries to re-encrypt at once")
+	rootCmd.AddCommand(reencryptCmd)
+}
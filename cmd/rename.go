@@ -3,11 +3,14 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/history"
 	"github.com/torbenconto/gopwd/internal/io"
+	"github.com/torbenconto/gopwd/internal/util"
 )
 
 var renameCmd = &cobra.Command{
@@ -21,22 +24,46 @@ var renameCmd = &cobra.Command{
 		service := args[0]
 		newService := args[1]
 
+		servicePath := util.ServicePath(VaultPath, service)
+		newServicePath := util.ServicePath(VaultPath, newService)
+
 		// Check if service exists
-		if !io.Exists(path.Join(VaultPath, service+".gpg")) {
+		if !io.Exists(servicePath) {
 			return fmt.Errorf("service %s not found", service)
 		}
 
 		// Check if new service already exists
-		if io.Exists(path.Join(VaultPath, newService+".gpg")) {
+		if io.Exists(newServicePath) {
 			return fmt.Errorf("service %s already exists", newService)
 		}
 
-		// Rename service
-		err := os.Rename(path.Join(VaultPath, service+".gpg"), path.Join(VaultPath, newService+".gpg"))
+		// Entries moved below another .gpg-id are encrypted for its recipients
+		same, err := util.SameRecipients(VaultPath, service, newService)
+		if err != nil {
+			return err
+		}
+		if same {
+			err = os.MkdirAll(filepath.Dir(newServicePath), 0755)
+			if err == nil {
+				err = os.Rename(servicePath, newServicePath)
+			}
+		} else {
+			err = util.CopyService(VaultPath, service, newService, crypt.Config{})
+			if err == nil {
+				err = os.Remove(servicePath)
+			}
+		}
 		if err != nil {
 			return fmt.Errorf("failed to rename service: %v", err)
 		}
 
+		err = history.Rename(VaultPath, service, newService)
+		if err != nil {
+			return fmt.Errorf("failed to move history: %v", err)
+		}
+
+		commitVault("Rename %s to %s", service, newService)
+
 		fmt.Printf("Service %s renamed to %s\n", service, newService)
 
 		return nil
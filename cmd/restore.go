@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"path/filepath"
+
 	"github.com/spf13/cobra"
 
 	"github.com/torbenconto/gopwd/internal/backup"
@@ -26,6 +28,10 @@ var restoreCmd = &cobra.Command{
 			return err
 		}
 
+		if restoreTo == VaultPath {
+			commitVault("Restore vault from %s", filepath.Base(archivePath))
+		}
+
 		return nil
 	},
 }
@@ -7,6 +7,8 @@ import (
 
 	"github.com/torbenconto/gopwd/internal/io"
 	"github.com/torbenconto/gopwd/internal/termio"
+	"github.com/torbenconto/gopwd/internal/trash"
+	"github.com/torbenconto/gopwd/internal/util"
 
 	"os"
 	"path"
@@ -20,7 +22,7 @@ var rmCmd = &cobra.Command{
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		service := args[0]
-		servicePath := path.Join(VaultPath, service) + ".gpg"
+		servicePath := util.ServicePath(VaultPath, service)
 		dirPath := path.Dir(servicePath)
 
 		if io.Exists(servicePath) {
@@ -29,9 +31,9 @@ var rmCmd = &cobra.Command{
 				return fmt.Errorf("failed to confirm action: %v", err)
 			}
 			if action {
-				err := io.RemoveFile(servicePath)
+				err := trash.Move(VaultPath, service, servicePath)
 				if err != nil {
-					return fmt.Errorf("failed to remove service: %s, error: %v", service, err)
+					return fmt.Errorf("failed to move service to trash: %s, error: %v", service, err)
 				}
 
 				// Check if the directory is empty and not the root vault directory
@@ -45,6 +47,12 @@ var rmCmd = &cobra.Command{
 						return fmt.Errorf("failed to remove directory: %s, error: %v", dirPath, err)
 					}
 				}
+
+				commitVault("Remove %s from vault", service)
+
+				fmt.Printf("Moved %s to trash, restore it with 'gopwd trash restore %s'\n", service, service)
+
+				purgeTrash()
 			}
 		}
 
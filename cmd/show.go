@@ -3,13 +3,13 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path"
 	"strings"
 
-	"github.com/atotto/clipboard"
 	"github.com/spf13/cobra"
 
-	"github.com/torbenconto/gopwd/internal/crypt/gpg"
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/entry"
+	"github.com/torbenconto/gopwd/internal/history"
 	"github.com/torbenconto/gopwd/internal/io"
 	"github.com/torbenconto/gopwd/internal/qr"
 	"github.com/torbenconto/gopwd/internal/util"
@@ -28,29 +28,58 @@ var showCmd = &cobra.Command{
 		qrFlag, _ := cmd.Flags().GetBool("qr")
 		copyFlag, _ := cmd.Flags().GetBool("copy")
 		lineNumber, _ := cmd.Flags().GetInt("line")
+		versionNumber, _ := cmd.Flags().GetInt("version")
+		fieldName, _ := cmd.Flags().GetString("field")
+
+		servicePath := util.ServicePath(VaultPath, service)
+		if versionNumber > 0 {
+			version, err := history.Get(VaultPath, service, versionNumber)
+			if err != nil {
+				return err
+			}
+			servicePath = version.Path
+		}
 
 		// Check if service exists
-		if !io.Exists(path.Join(VaultPath, service+".gpg")) {
+		if !io.Exists(servicePath) {
 			return fmt.Errorf("service %s not found", service)
 		}
 
 		// Get password and decrypt
-		file, err := io.ReadFile(path.Join(VaultPath, service+".gpg"))
+		file, err := io.ReadFile(servicePath)
 		if err != nil {
 			return fmt.Errorf("failed to read file: %v", err)
 		}
 
-		GPGID, err := util.ReadGPGID(path.Join(VaultPath, ".gpg-id"))
+		backend, err := util.NewBackend(VaultPath, service, crypt.Config{})
 		if err != nil {
-			return fmt.Errorf("failed to read gpg-id: %v", err)
+			return fmt.Errorf("failed to load encryption backend: %v", err)
 		}
-		GPGModule := gpg.NewGPG(GPGID, gpg.Config{})
 
-		password, err := GPGModule.Decrypt(file)
+		password, err := backend.Decrypt(file)
 		if err != nil {
 			return fmt.Errorf("failed to decrypt password: %v", err)
 		}
 
+		if fieldName != "" {
+			value, ok := entry.Parse(password).Get(fieldName)
+			if !ok {
+				return fmt.Errorf("field %s not found for %s", fieldName, service)
+			}
+			if qrFlag {
+				qr.Generate(value, qr.M, os.Stdout)
+			}
+			if copyFlag {
+				err = copySecret(cmd, value, fmt.Sprintf("Copied %s for %s to clipboard", fieldName, service))
+				if err != nil {
+					return fmt.Errorf("failed to copy field to clipboard: %w", err)
+				}
+			} else {
+				fmt.Println(value)
+			}
+			return nil
+		}
+
 		lines := strings.Split(string(password), "\n")
 		var nonEmptyLines []string
 		for _, line := range lines {
@@ -68,11 +97,10 @@ var showCmd = &cobra.Command{
 				qr.Generate(string(password), qr.M, os.Stdout)
 			}
 			if copyFlag {
-				err = clipboard.WriteAll(line)
+				err = copySecret(cmd, line, fmt.Sprintf("Copied line %d for %s to clipboard", lineNumber, service))
 				if err != nil {
 					return fmt.Errorf("failed to copy line to clipboard: %w", err)
 				}
-				fmt.Printf("Copied line %s for %s to clipboard\n", line, service)
 			} else {
 				fmt.Printf("%s\n", line)
 			}
@@ -86,11 +114,10 @@ var showCmd = &cobra.Command{
 		}
 
 		if copyFlag {
-			err = clipboard.WriteAll(string(password))
+			err = copySecret(cmd, string(password), fmt.Sprintf("Copied password for %s to clipboard", service))
 			if err != nil {
 				return fmt.Errorf("failed to copy password to clipboard: %w", err)
 			}
-			fmt.Printf("Copied password for %s to clipboard\n", service)
 		} else {
 			fmt.Println(string(password))
 		}
@@ -102,6 +129,9 @@ var showCmd = &cobra.Command{
 func init() {
 	showCmd.Flags().BoolP("qr", "q", false, "Show QR code of password")
 	showCmd.Flags().IntP("line", "l", 0, "Show a specific line of the file")
+	showCmd.Flags().StringP("field", "f", "", "Show a field of the file, such as username (password is the first line)")
 	showCmd.Flags().BoolP("copy", "c", false, "Copy password to clipboard")
+	showCmd.Flags().IntP("version", "v", 0, "Show an earlier version of the password (see gopwd history)")
+	addClipTimeoutFlag(showCmd)
 	rootCmd.AddCommand(showCmd)
 }
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/torbenconto/gopwd/internal/termio"
+	"github.com/torbenconto/gopwd/internal/trash"
+	"github.com/torbenconto/gopwd/internal/util"
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Command palette for deleted passwords",
+
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List deleted passwords",
+	Args:  cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		purgeTrash()
+
+		items, err := trash.List(VaultPath)
+		if err != nil {
+			return fmt.Errorf("failed to list trash: %v", err)
+		}
+
+		if len(items) == 0 {
+			fmt.Println("Trash is empty")
+			return nil
+		}
+
+		for _, item := range items {
+			fmt.Printf("%s  %s  %s\n", item.DeletedAt.Format(time.DateTime), item.ID, item.Service)
+		}
+
+		return nil
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:               "restore [service] [flags]",
+	Short:             "Restore a deleted password",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: autocompleteTrash,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		service := args[0]
+		id, _ := cmd.Flags().GetString("id")
+
+		item, err := trash.Find(VaultPath, service, id)
+		if err != nil {
+			return err
+		}
+
+		_, err = trash.Restore(VaultPath, item)
+		if err != nil {
+			return fmt.Errorf("failed to restore %s: %v", service, err)
+		}
+
+		commitVault("Restore %s from trash", service)
+
+		fmt.Printf("Restored %s deleted on %s\n", service, item.DeletedAt.Format(time.DateTime))
+
+		return nil
+	},
+}
+
+var trashPurgeCmd = &cobra.Command{
+	Use:               "purge [service] [flags]",
+	Short:             "Permanently delete passwords in the trash",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: autocompleteTrash,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		items, err := trash.List(VaultPath)
+		if err != nil {
+			return fmt.Errorf("failed to list trash: %v", err)
+		}
+
+		if len(args) > 0 {
+			var matching []trash.Item
+			for _, item := range items {
+				if item.Service == args[0] {
+					matching = append(matching, item)
+				}
+			}
+			if len(matching) == 0 {
+				return fmt.Errorf("service %s not found in trash", args[0])
+			}
+			items = matching
+		}
+
+		if len(items) == 0 {
+			fmt.Println("Trash is empty")
+			return nil
+		}
+
+		fmt.Printf("%d passwords will be deleted permanently. This action cannot be undone.\n", len(items))
+		action, err := termio.ConfirmAction()
+		if err != nil {
+			return fmt.Errorf("failed to confirm action: %v", err)
+		}
+		if !action {
+			fmt.Println("Aborted")
+			return nil
+		}
+
+		err = trash.Purge(VaultPath, items)
+		if err != nil {
+			return err
+		}
+
+		commitVault("Purge %d entries from trash", len(items))
+
+		fmt.Printf("Purged %d passwords\n", len(items))
+
+		return nil
+	},
+}
+
+// purgeTrash applies the trashRetentionDays setting.
+func purgeTrash() {
+	purged, err := util.AutoPurgeTrash(VaultPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to purge trash: %v\n", err)
+		return
+	}
+	if purged > 0 {
+		commitVault("Purge %d expired entries from trash", purged)
+	}
+}
+
+// autocompleteTrash provides autocompletion for deleted service names.
+func autocompleteTrash(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	items, err := trash.List(VaultPath)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var services []string
+	for _, item := range items {
+		services = append(services, item.Service)
+	}
+	return services, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	trashRestoreCmd.Flags().String("id", "", "ID of the deleted copy to restore (see gopwd trash list)")
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashPurgeCmd)
+	rootCmd.AddCommand(trashCmd)
+}
@@ -1,11 +1,14 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
 	"path"
 	"path/filepath"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -13,10 +16,17 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sevlyar/go-daemon"
 
-	"github.com/torbenconto/gopwd/internal/crypt/gpg"
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/entry"
+	"github.com/torbenconto/gopwd/internal/git"
+	"github.com/torbenconto/gopwd/internal/history"
 	"github.com/torbenconto/gopwd/internal/io"
+	"github.com/torbenconto/gopwd/internal/otp"
+	"github.com/torbenconto/gopwd/internal/policy"
 	"github.com/torbenconto/gopwd/internal/pwgen"
+	"github.com/torbenconto/gopwd/internal/search"
 	"github.com/torbenconto/gopwd/internal/ssl"
+	"github.com/torbenconto/gopwd/internal/trash"
 	"github.com/torbenconto/gopwd/internal/util"
 )
 
@@ -51,6 +61,35 @@ func setupRouter(vaultPath string) *gin.Engine {
 		})
 	})
 
+	r.GET("/search", func(c *gin.Context) {
+		terms := strings.Fields(strings.Join(c.QueryArray("q"), " "))
+		if len(terms) == 0 {
+			c.JSON(400, gin.H{
+				"message": "missing search terms",
+			})
+			return
+		}
+
+		services, err := io.ListServices(vaultPath)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"message": "error listing services: " + err.Error(),
+			})
+			return
+		}
+
+		if c.Query("fuzzy") == "true" {
+			c.JSON(200, gin.H{
+				"results": search.Fuzzy(services, terms),
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"services": search.Match(services, terms),
+		})
+	})
+
 	r.POST("/get", func(c *gin.Context) {
 		var req struct {
 			Service     string `json:"service"`
@@ -63,15 +102,17 @@ func setupRouter(vaultPath string) *gin.Engine {
 			return
 		}
 
+		servicePath := util.ServicePath(vaultPath, req.Service)
+
 		// Check if service exists
-		if !io.Exists(filepath.Join(vaultPath, req.Service+".gpg")) {
+		if !io.Exists(servicePath) {
 			c.JSON(400, gin.H{
 				"message": "service doesn't exist",
 			})
 			return
 		}
 
-		file, err := io.ReadFile(filepath.Join(vaultPath, req.Service+".gpg"))
+		file, err := io.ReadFile(servicePath)
 		if err != nil {
 			c.JSON(500, gin.H{
 				"message": "error reading file",
@@ -79,27 +120,20 @@ func setupRouter(vaultPath string) *gin.Engine {
 			return
 		}
 
-		// Decrypt file
-		gpgID, err := util.ReadGPGID(filepath.Join(vaultPath, ".gpg-id"))
+		// Initialize the encryption backend without interactive prompts
+		backend, err := util.NewBackend(vaultPath, req.Service, crypt.Config{
+			Passphrase: req.GpgPassword,
+			Batch:      true,
+		})
 		if err != nil {
 			c.JSON(500, gin.H{
-				"message": "error reading gpg-id: " + err.Error(),
+				"message": "error loading encryption backend: " + err.Error(),
 			})
 			return
 		}
 
-		args := []string{"--quiet", "--yes", "--compress-algo=none", "--no-encrypt-to", "--no-auto-check-trustdb", "--batch", "--pinentry-mode=loopback"}
-		if req.GpgPassword != "" {
-			args = append(args, "--passphrase", req.GpgPassword)
-		}
-
-		// Initialize GPG module with configuration
-		gpgModule := gpg.NewGPG(gpgID, gpg.Config{
-			Args: args,
-		})
-
 		// Attempt to decrypt the file
-		decrypted, err := gpgModule.Decrypt(file)
+		decrypted, err := backend.Decrypt(file)
 		if err != nil {
 			c.JSON(500, gin.H{
 				"message": "error decrypting file: " + err.Error(),
@@ -107,12 +141,58 @@ func setupRouter(vaultPath string) *gin.Engine {
 			return
 		}
 
-		// Successfully decrypted, return the content
+		// Successfully decrypted, return the content and its fields
+		parsed := entry.Parse(decrypted)
 		c.JSON(200, gin.H{
 			"password": string(decrypted),
+			"fields":   parsed.FieldMap(),
+			"notes":    parsed.Notes(),
 		})
 	})
-	r.POST("/update", func(c *gin.Context) {
+	r.POST("/otp", lockVault, func(c *gin.Context) {
+		var req struct {
+			Service     string `json:"service"`
+			GpgPassword string `json:"gpg_password"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"message": "invalid request",
+			})
+			return
+		}
+
+		// Check if service exists
+		if !io.Exists(util.ServicePath(vaultPath, req.Service)) {
+			c.JSON(400, gin.H{
+				"message": "service doesn't exist",
+			})
+			return
+		}
+
+		key, code, err := util.NextOTP(vaultPath, req.Service, crypt.Config{
+			Passphrase: req.GpgPassword,
+			Batch:      true,
+		})
+		if err != nil {
+			c.JSON(500, gin.H{
+				"message": "error generating one-time code: " + err.Error(),
+			})
+			return
+		}
+
+		response := gin.H{
+			"code": code,
+			"type": key.Type,
+		}
+		if key.Type == otp.TOTP {
+			response["remaining"] = int(key.Remaining(time.Now()).Seconds())
+		} else {
+			commit(vaultPath, "Increment HOTP counter for %s using API", req.Service)
+		}
+
+		c.JSON(200, response)
+	})
+	r.POST("/update", lockVault, func(c *gin.Context) {
 		var req struct {
 			Service    string `json:"service"`
 			NewContent string `json:"new_content"`
@@ -124,28 +204,27 @@ func setupRouter(vaultPath string) *gin.Engine {
 			return
 		}
 
+		servicePath := util.ServicePath(vaultPath, req.Service)
+
 		// Check if service exists
-		if !io.Exists(filepath.Join(vaultPath, req.Service+".gpg")) {
+		if !io.Exists(servicePath) {
 			c.JSON(400, gin.H{
 				"message": "service doesn't exist",
 			})
 			return
 		}
 
-		// Encrypt the password
-		gpgID, err := util.ReadGPGID(filepath.Join(vaultPath, ".gpg-id"))
+		// Initialize the encryption backend
+		backend, err := util.NewBackend(vaultPath, req.Service, crypt.Config{})
 		if err != nil {
 			c.JSON(500, gin.H{
-				"message": "error reading gpg-id",
+				"message": "error loading encryption backend",
 			})
 			return
 		}
 
-		// Initialize GPG module with configuration
-		gpgModule := gpg.NewGPG(gpgID, gpg.Config{})
-
 		// Encrypt the password
-		encrypted, err := gpgModule.Encrypt([]byte(req.NewContent))
+		encrypted, err := backend.Encrypt([]byte(req.NewContent))
 		if err != nil {
 			c.JSON(500, gin.H{
 				"message": "error encrypting password",
@@ -153,8 +232,17 @@ func setupRouter(vaultPath string) *gin.Engine {
 			return
 		}
 
-		// Write the encrypted password to the file
-		err = io.WriteFile(filepath.Join(vaultPath, req.Service+".gpg"), encrypted)
+		// Keep the previous version before overwriting it
+		err = history.Save(vaultPath, req.Service, servicePath)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"message": "error saving previous version",
+			})
+			return
+		}
+
+		// Replace the file in one step, so readers never see a partly written entry
+		err = io.WriteFileAtomic(servicePath, encrypted)
 		if err != nil {
 			c.JSON(500, gin.H{
 				"message": "error writing file",
@@ -162,11 +250,13 @@ func setupRouter(vaultPath string) *gin.Engine {
 			return
 		}
 
+		commit(vaultPath, "Update password for %s using API", req.Service)
+
 		c.JSON(200, gin.H{
 			"message": "password updated",
 		})
 	})
-	r.POST("/delete", func(c *gin.Context) {
+	r.POST("/delete", lockVault, func(c *gin.Context) {
 		var req struct {
 			Service string `json:"service"`
 		}
@@ -178,23 +268,25 @@ func setupRouter(vaultPath string) *gin.Engine {
 			return
 		}
 
+		servicePath := util.ServicePath(vaultPath, req.Service)
+
 		// Check if service exists
-		if !io.Exists(filepath.Join(vaultPath, req.Service+".gpg")) {
+		if !io.Exists(servicePath) {
 			c.JSON(400, gin.H{
 				"message": "service doesn't exist",
 			})
 			return
 		}
 
-		err := os.Remove(filepath.Join(vaultPath, req.Service+".gpg"))
+		err := trash.Move(vaultPath, req.Service, servicePath)
 		if err != nil {
 			c.JSON(500, gin.H{
-				"message": "error deleting file: " + err.Error(),
+				"message": "error moving file to trash: " + err.Error(),
 			})
 			return
 		}
 
-		dirPath := path.Dir(filepath.Join(vaultPath, req.Service+".gpg"))
+		dirPath := path.Dir(servicePath)
 		isEmpty, err := io.IsDirEmpty(dirPath)
 		if err != nil {
 			c.JSON(500, gin.H{
@@ -212,11 +304,18 @@ func setupRouter(vaultPath string) *gin.Engine {
 			}
 		}
 
+		_, err = util.AutoPurgeTrash(vaultPath)
+		if err != nil {
+			fmt.Println("Error purging trash:", err)
+		}
+
+		commit(vaultPath, "Remove %s from vault using API", req.Service)
+
 		c.JSON(200, gin.H{
-			"message": "file deleted",
+			"message": "file moved to trash",
 		})
 	})
-	r.POST("/insert", func(c *gin.Context) {
+	r.POST("/insert", lockVault, func(c *gin.Context) {
 		var req struct {
 			Service string `json:"service"`
 			Content string `json:"content"`
@@ -228,28 +327,27 @@ func setupRouter(vaultPath string) *gin.Engine {
 			return
 		}
 
+		servicePath := util.ServicePath(vaultPath, req.Service)
+
 		// Check if service already exists
-		if io.Exists(filepath.Join(vaultPath, req.Service+".gpg")) {
+		if io.Exists(servicePath) {
 			c.JSON(400, gin.H{
 				"message": "service already exists",
 			})
 			return
 		}
 
-		// Encrypt the password
-		gpgID, err := util.ReadGPGID(filepath.Join(vaultPath, ".gpg-id"))
+		// Initialize the encryption backend
+		backend, err := util.NewBackend(vaultPath, req.Service, crypt.Config{})
 		if err != nil {
 			c.JSON(500, gin.H{
-				"message": "error reading gpg-id: " + err.Error(),
+				"message": "error loading encryption backend: " + err.Error(),
 			})
 			return
 		}
 
-		// Initialize GPG module with configuration
-		gpgModule := gpg.NewGPG(gpgID, gpg.Config{})
-
 		// Encrypt the password
-		encrypted, err := gpgModule.Encrypt([]byte(req.Content))
+		encrypted, err := backend.Encrypt([]byte(req.Content))
 		if err != nil {
 			c.JSON(500, gin.H{
 				"message": "error encrypting password: " + err.Error(),
@@ -257,54 +355,102 @@ func setupRouter(vaultPath string) *gin.Engine {
 			return
 		}
 
-		err = util.CreateStructureAndClean(req.Service, vaultPath, filepath.Join(vaultPath, req.Service+".gpg"), encrypted)
+		err = util.CreateStructureAndClean(req.Service, vaultPath, servicePath, encrypted)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"message": "error creating structure: " + err.Error(),
+			})
+			return
+		}
+
+		commit(vaultPath, "Add given password for %s using API", req.Service)
 
 		c.JSON(200, gin.H{
 			"message": "password inserted",
 		})
 	})
-	r.POST("/generate", func(c *gin.Context) {
-		req := &struct {
-			Service   string `json:"service"`
-			Length    int    `json:"length"`
-			Humanized bool   `json:"humanized"`
-			Symbols   bool   `json:"symbols"`
-			Numbers   bool   `json:"numbers"`
-			Lowercase bool   `json:"lowercase"`
-			Uppercase bool   `json:"uppercase"`
-		}{
-			Length:    16,
-			Symbols:   true,
-			Numbers:   true,
-			Lowercase: true,
-			Uppercase: true,
+	r.POST("/generate", lockVault, func(c *gin.Context) {
+		body, err := c.GetRawData()
+		if err != nil {
+			c.JSON(400, gin.H{
+				"message": "invalid request",
+			})
+			return
 		}
 
-		if err := c.BindJSON(&req); err != nil {
+		req := &struct {
+			Service     string `json:"service"`
+			Policy      string `json:"policy"`
+			Wordlist    string `json:"wordlist"`
+			InPlace     bool   `json:"in_place"`
+			GpgPassword string `json:"gpg_password"`
+		}{}
+		if err := json.Unmarshal(body, req); err != nil {
 			c.JSON(400, gin.H{
 				"message": "invalid request",
 			})
 			return
 		}
 
-		servicePath := path.Join(vaultPath, req.Service) + ".gpg"
+		servicePath := util.ServicePath(vaultPath, req.Service)
 
-		if io.Exists(servicePath) {
+		if req.InPlace && !io.Exists(servicePath) {
 			c.JSON(400, gin.H{
-				"message": "service already exists",
+				"message": "service doesn't exist",
+			})
+			return
+		}
+
+		if !req.InPlace && io.Exists(servicePath) {
+			c.JSON(400, gin.H{
+				"message": "service already exists, use in_place to replace its password",
+			})
+			return
+		}
+
+		// Wordlist files are only read from the command line
+		if req.Wordlist != "" && req.Wordlist != pwgen.WordlistBIP39 && req.Wordlist != pwgen.WordlistEFF {
+			c.JSON(400, gin.H{
+				"message": "unknown wordlist, use bip39 or eff",
+			})
+			return
+		}
+
+		// Options in the request take precedence over the policy of the service
+		config := pwgen.DefaultConfig()
+		policyName, err := policy.Resolve(req.Service, req.Policy)
+		if err != nil {
+			c.JSON(400, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		if policyName != "" {
+			err = policy.Apply(policyName, &config)
+			if err != nil {
+				c.JSON(500, gin.H{
+					"message": err.Error(),
+				})
+				return
+			}
+		}
+		if err := json.Unmarshal(body, &config); err != nil {
+			c.JSON(400, gin.H{
+				"message": "invalid request",
 			})
 			return
 		}
 
 		// Generate password
-		passwordGenerator := pwgen.NewPasswordGenerator(pwgen.PasswordGeneratorConfig{
-			Length:    req.Length,
-			Humanized: req.Humanized,
-			Symbols:   req.Symbols,
-			Numbers:   req.Numbers,
-			Lowercase: req.Lowercase,
-			Uppercase: req.Uppercase,
-		})
+		passwordGenerator := pwgen.NewPasswordGenerator(config)
+
+		entropy, err := passwordGenerator.Entropy()
+		if err != nil {
+			c.JSON(400, gin.H{
+				"message": "error generating password: " + err.Error(),
+			})
+			return
+		}
 
 		password, err := passwordGenerator.Generate()
 		if err != nil {
@@ -314,19 +460,41 @@ func setupRouter(vaultPath string) *gin.Engine {
 			return
 		}
 
-		gpgID, err := util.ReadGPGID(filepath.Join(vaultPath, ".gpg-id"))
+		if req.InPlace {
+			setAt, err := util.ReplacePassword(vaultPath, req.Service, password, crypt.Config{
+				Passphrase: req.GpgPassword,
+				Batch:      true,
+			})
+			if err != nil {
+				c.JSON(500, gin.H{
+					"message": "error replacing password: " + err.Error(),
+				})
+				return
+			}
+
+			commit(vaultPath, "Rotate generated password for %s using API", req.Service)
+
+			c.JSON(200, gin.H{
+				"message":      "password generated and replaced",
+				"password":     password,
+				"entropy":      entropy,
+				"policy":       policyName,
+				"old_age_days": int(time.Since(setAt).Hours() / 24),
+			})
+			return
+		}
+
+		// Initialize the encryption backend
+		backend, err := util.NewBackend(vaultPath, req.Service, crypt.Config{})
 		if err != nil {
 			c.JSON(500, gin.H{
-				"message": "error reading gpg-id: " + err.Error(),
+				"message": "error loading encryption backend: " + err.Error(),
 			})
 			return
 		}
 
-		// Initialize GPG module with configuration
-		gpgModule := gpg.NewGPG(gpgID, gpg.Config{})
-
 		// Encrypt the password
-		encrypted, err := gpgModule.Encrypt([]byte(password))
+		encrypted, err := backend.Encrypt([]byte(password))
 		if err != nil {
 			c.JSON(500, gin.H{
 				"message": "error encrypting password: " + err.Error(),
@@ -342,15 +510,40 @@ func setupRouter(vaultPath string) *gin.Engine {
 			return
 		}
 
+		commit(vaultPath, "Add generated password for %s using API", req.Service)
+
 		c.JSON(200, gin.H{
 			"message":  "password generated and inserted",
 			"password": password,
+			"entropy":  entropy,
+			"policy":   policyName,
 		})
 	})
 
 	return r
 }
 
+// vaultMu serialises the requests that change the vault, so each change and its commit happen
+// together and concurrent commits do not race on the git index.
+var vaultMu sync.Mutex
+
+// lockVault holds vaultMu while the rest of the request is handled.
+func lockVault(c *gin.Context) {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+
+	c.Next()
+}
+
+// commit records a change made through the API when git is enabled for the vault. The
+// change already succeeded, so a failed commit is only logged.
+func commit(vaultPath, format string, a ...any) {
+	err := git.AutoCommit(vaultPath, fmt.Sprintf(format, a...))
+	if err != nil {
+		fmt.Println("Error committing change to git:", err)
+	}
+}
+
 func start(vaultPath, addr, certPath, keyPath string) {
 	// Check if cert exists
 	if !io.Exists(certPath) || !io.Exists(keyPath) {
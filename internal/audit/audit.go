@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"sort"
+	"time"
+
+	"github.com/torbenconto/gopwd/internal/strength"
+)
+
+// Issues found by Run.
+const (
+	Breached = "breached"
+	Reused   = "reused"
+	Short    = "short"
+	Weak     = "weak"
+	Old      = "old"
+)
+
+// weights rank the issues when sorting a report.
+var weights = map[string]int{Breached: 5, Reused: 4, Weak: 3, Short: 2, Old: 1}
+
+// Options are the thresholds of an audit.
+type Options struct {
+	// MinLength is the length below which a password is short.
+	MinLength int
+	// MinBits is the estimated entropy below which a password is weak.
+	MinBits float64
+	// MaxAge is the time since the last change after which an entry is old. 0 disables the check.
+	MaxAge time.Duration
+}
+
+// Entry is a decrypted password and when its entry was last changed.
+type Entry struct {
+	Service  string
+	Password string
+	Modified time.Time
+	// Breaches is how often the password appears in a breach database.
+	Breaches int
+}
+
+// Finding is an audited entry with the issues found in it.
+type Finding struct {
+	Service    string          `json:"service"`
+	Issues     []string        `json:"issues"`
+	Length     int             `json:"length"`
+	Strength   strength.Result `json:"strength"`
+	AgeDays    int             `json:"age_days"`
+	ReusedWith []string        `json:"reused_with,omitempty"`
+	Breaches   int             `json:"breaches,omitempty"`
+}
+
+// Report is the result of an audit.
+type Report struct {
+	Audited  int       `json:"audited"`
+	Findings []Finding `json:"findings"`
+}
+
+// Run audits entries and returns the ones with issues, the most serious first.
+func Run(entries []Entry, options Options, now time.Time) *Report {
+	byPassword := map[string][]string{}
+	for _, e := range entries {
+		if e.Password != "" {
+			byPassword[e.Password] = append(byPassword[e.Password], e.Service)
+		}
+	}
+
+	report := &Report{Audited: len(entries), Findings: []Finding{}}
+	for _, e := range entries {
+		finding := Finding{
+			Service:  e.Service,
+			Length:   len([]rune(e.Password)),
+			Strength: strength.Estimate(e.Password),
+			AgeDays:  int(now.Sub(e.Modified).Hours() / 24),
+			Breaches: e.Breaches,
+		}
+
+		if e.Breaches > 0 {
+			finding.Issues = append(finding.Issues, Breached)
+		}
+
+		for _, service := range byPassword[e.Password] {
+			if service != e.Service {
+				finding.ReusedWith = append(finding.ReusedWith, service)
+			}
+		}
+		if len(finding.ReusedWith) > 0 {
+			finding.Issues = append(finding.Issues, Reused)
+		}
+		if finding.Strength.Bits < options.MinBits {
+			finding.Issues = append(finding.Issues, Weak)
+		}
+		if finding.Length < options.MinLength {
+			finding.Issues = append(finding.Issues, Short)
+		}
+		if options.MaxAge > 0 && now.Sub(e.Modified) > options.MaxAge {
+			finding.Issues = append(finding.Issues, Old)
+		}
+
+		if len(finding.Issues) > 0 {
+			report.Findings = append(report.Findings, finding)
+		}
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := severity(report.Findings[i]), severity(report.Findings[j])
+		if a != b {
+			return a > b
+		}
+		if report.Findings[i].Strength.Bits != report.Findings[j].Strength.Bits {
+			return report.Findings[i].Strength.Bits < report.Findings[j].Strength.Bits
+		}
+		return report.Findings[i].Service < report.Findings[j].Service
+	})
+
+	return report
+}
+
+func severity(finding Finding) int {
+	total := 0
+	for _, issue := range finding.Issues {
+		total += weights[issue]
+	}
+	return total
+}
@@ -0,0 +1,138 @@
+package breach
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// hashLength is the length of a hex encoded SHA-1 hash.
+const hashLength = 40
+
+// DB is a Pwned Passwords SHA-1 dump: lines of "HASH:COUNT" sorted by hash. It is searched in
+// place with a binary search over byte offsets, so even the full dump is never read into memory.
+// A DB is safe for concurrent use.
+type DB struct {
+	file *os.File
+	size int64
+}
+
+// Open opens the dump at path.
+func Open(path string) (*DB, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breach database: %v", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open breach database: %v", err)
+	}
+
+	return &DB{file: file, size: info.Size()}, nil
+}
+
+// Close closes the dump.
+func (db *DB) Close() error {
+	return db.file.Close()
+}
+
+// Count returns how often password appears in the dump, 0 if it was never breached.
+func (db *DB) Count(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	return db.CountHash(hex.EncodeToString(sum[:]))
+}
+
+// CountHash returns the count of the SHA-1 hash in the dump, 0 if it is missing.
+func (db *DB) CountHash(hash string) (int, error) {
+	target := []byte(strings.ToUpper(hash))
+
+	// The line of target, if any, starts in [lo, hi)
+	lo, hi := int64(0), db.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		start, line, err := db.lineFrom(mid)
+		if err != nil {
+			return 0, err
+		}
+		if start >= hi {
+			hi = mid
+			continue
+		}
+
+		lineHash, count, _ := bytes.Cut(line, []byte(":"))
+		switch bytes.Compare(bytes.ToUpper(lineHash), target) {
+		case 0:
+			n, err := strconv.Atoi(string(bytes.TrimSpace(count)))
+			if err != nil {
+				return 0, fmt.Errorf("invalid breach database line %q", line)
+			}
+			return n, nil
+		case -1:
+			lo = start + int64(len(line)) + 1
+		default:
+			hi = mid
+		}
+	}
+
+	return 0, nil
+}
+
+// lineFrom returns the first line starting at or after offset, without its line ending.
+func (db *DB) lineFrom(offset int64) (int64, []byte, error) {
+	start := offset
+	if offset > 0 {
+		// Skip the rest of the line offset falls into
+		newline, err := db.indexByte(offset-1, '\n')
+		if err != nil {
+			return 0, nil, err
+		}
+		if newline < 0 {
+			return db.size, nil, nil
+		}
+		start = newline + 1
+	}
+
+	end, err := db.indexByte(start, '\n')
+	if err != nil {
+		return 0, nil, err
+	}
+	if end < 0 {
+		end = db.size
+	}
+
+	line := make([]byte, end-start)
+	_, err = db.file.ReadAt(line, start)
+	if err != nil && err != io.EOF {
+		return 0, nil, err
+	}
+
+	return start, bytes.TrimSuffix(line, []byte("\r")), nil
+}
+
+// indexByte returns the offset of the first c at or after offset, or -1.
+func (db *DB) indexByte(offset int64, c byte) (int64, error) {
+	buf := make([]byte, 2*hashLength)
+	for offset < db.size {
+		n, err := db.file.ReadAt(buf, offset)
+		if i := bytes.IndexByte(buf[:n], c); i >= 0 {
+			return offset + int64(i), nil
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		offset += int64(n)
+	}
+
+	return -1, nil
+}
@@ -0,0 +1,116 @@
+package breach
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeDump writes a sorted dump of the hashes of password0 to password{n-1}, counting i for
+// password{i}, and returns its path and the hashes in file order.
+func writeDump(t *testing.T, n int, newline string, final bool) (string, []string, map[string]int) {
+	t.Helper()
+
+	counts := map[string]int{}
+	var hashes []string
+	for i := 0; i < n; i++ {
+		sum := sha1.Sum([]byte(fmt.Sprintf("password%d", i)))
+		hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+		hashes = append(hashes, hash)
+		counts[hash] = i + 1
+	}
+	sort.Strings(hashes)
+
+	var lines []string
+	for _, hash := range hashes {
+		lines = append(lines, fmt.Sprintf("%s:%d", hash, counts[hash]))
+	}
+	content := strings.Join(lines, newline)
+	if final {
+		content += newline
+	}
+
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	err := os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, hashes, counts
+}
+
+func TestCountHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		newline string
+		final   bool
+	}{
+		{"lf", 50, "\n", true},
+		{"lf without final newline", 50, "\n", false},
+		{"crlf", 50, "\r\n", true},
+		{"crlf without final newline", 50, "\r\n", false},
+		{"single line", 1, "\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, hashes, counts := writeDump(t, tt.n, tt.newline, tt.final)
+			db, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			// Every entry, including the first and the last, in either case
+			for i, hash := range hashes {
+				if i%2 == 1 {
+					hash = strings.ToLower(hash)
+				}
+				got, err := db.CountHash(hash)
+				if err != nil {
+					t.Fatalf("CountHash(%s) error: %v", hash, err)
+				}
+				if want := counts[strings.ToUpper(hash)]; got != want {
+					t.Errorf("CountHash(%s) = %d, want %d", hash, got, want)
+				}
+			}
+
+			// Misses before the first entry, after the last and between two
+			misses := []string{
+				strings.Repeat("0", hashLength),
+				strings.Repeat("F", hashLength),
+				hashes[0][:hashLength-1] + "Z",
+			}
+			for _, hash := range misses {
+				got, err := db.CountHash(hash)
+				if err != nil {
+					t.Fatalf("CountHash(%s) error: %v", hash, err)
+				}
+				if got != 0 {
+					t.Errorf("CountHash(%s) = %d, want 0", hash, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	path, _, _ := writeDump(t, 20, "\n", true)
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if got, err := db.Count("password7"); err != nil || got != 8 {
+		t.Errorf("Count(password7) = %d, %v, want 8", got, err)
+	}
+	if got, err := db.Count("correct horse battery staple"); err != nil || got != 0 {
+		t.Errorf("Count of a missing password = %d, %v, want 0", got, err)
+	}
+}
@@ -0,0 +1,111 @@
+package clip
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/atotto/clipboard"
+)
+
+// DefaultTimeout is how long a copied secret stays on the clipboard.
+const DefaultTimeout = 45 * time.Second
+
+// RestoreCommand is the hidden gopwd command that runs Restore in the background process.
+const RestoreCommand = "clip-restore"
+
+// restoreRequest is passed to the background process on stdin, so neither the secret nor the
+// previous clipboard contents show up in its arguments.
+type restoreRequest struct {
+	Timeout  time.Duration `json:"timeout"`
+	Previous string        `json:"previous"`
+	Sum      string        `json:"sum"`
+}
+
+// Copy writes text to the clipboard. If timeout is positive, a detached gopwd process restores
+// the previous clipboard contents once it expires.
+func Copy(text string, timeout time.Duration) error {
+	previous, _ := clipboard.ReadAll()
+
+	err := clipboard.WriteAll(text)
+	if err != nil {
+		return err
+	}
+
+	if timeout <= 0 {
+		return nil
+	}
+
+	err = startRestore(restoreRequest{
+		Timeout:  timeout,
+		Previous: previous,
+		Sum:      sum(text),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule clipboard restore: %v", err)
+	}
+
+	return nil
+}
+
+func startRestore(req restoreRequest) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(executable, RestoreCommand)
+	cmd.SysProcAttr = detached()
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	err = json.NewEncoder(stdin).Encode(req)
+	stdin.Close()
+	if err != nil {
+		return err
+	}
+
+	return cmd.Process.Release()
+}
+
+// Restore reads a request written by Copy, waits for its timeout and puts the previous contents
+// back, unless the clipboard no longer holds the copied secret.
+func Restore(r io.Reader) error {
+	var req restoreRequest
+	err := json.NewDecoder(r).Decode(&req)
+	if err != nil {
+		return fmt.Errorf("failed to read clipboard restore request: %v", err)
+	}
+
+	time.Sleep(req.Timeout)
+
+	current, err := clipboard.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	// Something else was copied since, leave it alone
+	if sum(current) != req.Sum {
+		return nil
+	}
+
+	return clipboard.WriteAll(req.Previous)
+}
+
+func sum(text string) string {
+	hash := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,10 @@
+//go:build linux || darwin
+
+package clip
+
+import "syscall"
+
+// detached starts the restore process in its own session so closing the terminal doesn't kill it.
+func detached() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
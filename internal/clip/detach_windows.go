@@ -0,0 +1,13 @@
+//go:build windows
+
+package clip
+
+import "syscall"
+
+// detachedProcess is the DETACHED_PROCESS process creation flag.
+const detachedProcess = 0x00000008
+
+// detached starts the restore process without a console so closing the terminal doesn't kill it.
+func detached() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: detachedProcess}
+}
@@ -0,0 +1,92 @@
+package age
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+
+	"github.com/torbenconto/gopwd/internal/io"
+)
+
+// Name identifies the age backend in .gopwd.yaml.
+const Name = "age"
+
+// Age encrypts entries with age, for X25519 (age1...) and SSH recipients.
+type Age struct {
+	ids        []string
+	recipients []age.Recipient
+	identities string
+	passphrase string
+	batch      bool
+
+	// loadOnce loads the identities on first use, so encrypted SSH keys, which keep their
+	// unlocked key, ask for the passphrase only once.
+	loadOnce sync.Once
+	loaded   []age.Identity
+	loadErr  error
+	// unlockMu serializes decryption, which may unlock an SSH key on first use.
+	unlockMu sync.Mutex
+}
+
+type Config struct {
+	// Identities is a file of age identities or an SSH private key. Several files can be
+	// given, separated by the OS path list separator.
+	Identities string
+	// Passphrase unlocks encrypted SSH keys.
+	Passphrase string
+	// Batch disables the passphrase prompt.
+	Batch bool
+}
+
+func NewAge(ids []string, config Config) (*Age, error) {
+	if config.Identities == "" {
+		config.Identities = DefaultIdentities()
+	}
+
+	recipients := make([]age.Recipient, 0, len(ids))
+	for _, id := range ids {
+		recipient, err := ParseRecipient(id)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	return &Age{
+		ids:        ids,
+		recipients: recipients,
+		identities: config.Identities,
+		passphrase: config.Passphrase,
+		batch:      config.Batch,
+	}, nil
+}
+
+// DefaultIdentities returns ~/.gopwd/identities.
+func DefaultIdentities() string {
+	return filepath.Join(io.GetHomeDir(), ".gopwd", "identities")
+}
+
+// ParseRecipient parses an X25519 (age1...) or SSH (ssh-ed25519, ssh-rsa) public key.
+func ParseRecipient(id string) (age.Recipient, error) {
+	id = strings.TrimSpace(id)
+	switch {
+	case strings.HasPrefix(id, "age1"):
+		return age.ParseX25519Recipient(id)
+	case strings.HasPrefix(id, "ssh-"):
+		return agessh.ParseRecipient(id)
+	}
+
+	return nil, fmt.Errorf("unknown age recipient %q", id)
+}
+
+func (a *Age) Name() string {
+	return Name
+}
+
+func (a *Age) Recipients() []string {
+	return a.ids
+}
@@ -0,0 +1,106 @@
+package age
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"golang.org/x/crypto/ssh"
+
+	iou "github.com/torbenconto/gopwd/internal/io"
+	"github.com/torbenconto/gopwd/internal/termio"
+)
+
+// promptMu keeps backends for different recipients files from asking for passphrases at the
+// same time.
+var promptMu sync.Mutex
+
+func (a *Age) Decrypt(ciphertext []byte) ([]byte, error) {
+	a.loadOnce.Do(func() {
+		a.loaded, a.loadErr = a.loadIdentities()
+	})
+	if a.loadErr != nil {
+		return nil, a.loadErr
+	}
+
+	a.unlockMu.Lock()
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), a.loaded...)
+	a.unlockMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
+
+func (a *Age) loadIdentities() ([]age.Identity, error) {
+	var identities []age.Identity
+
+	for _, file := range filepath.SplitList(a.identities) {
+		data, err := iou.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read identities: %v", err)
+		}
+
+		if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN")) {
+			ids, err := age.ParseIdentities(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse identities %s: %v", file, err)
+			}
+			identities = append(identities, ids...)
+			continue
+		}
+
+		id, err := a.parseSSHIdentity(file, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse identities %s: %v", file, err)
+		}
+		identities = append(identities, id)
+	}
+
+	return identities, nil
+}
+
+// parseSSHIdentity reads an SSH private key, asking for its passphrase only when an entry
+// is actually encrypted to it.
+func (a *Age) parseSSHIdentity(file string, data []byte) (age.Identity, error) {
+	id, err := agessh.ParseIdentity(data)
+	if err == nil {
+		return id, nil
+	}
+
+	var missing *ssh.PassphraseMissingError
+	if !errors.As(err, &missing) {
+		return nil, err
+	}
+
+	pubKey := missing.PublicKey
+	if pubKey == nil {
+		pubData, err := iou.ReadFile(file + ".pub")
+		if err != nil {
+			return nil, fmt.Errorf("encrypted key without a public key: %v", err)
+		}
+		pubKey, _, _, _, err = ssh.ParseAuthorizedKey(pubData)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return agessh.NewEncryptedSSHIdentity(pubKey, data, func() ([]byte, error) {
+		if a.passphrase != "" {
+			return []byte(a.passphrase), nil
+		}
+		if a.batch {
+			return nil, fmt.Errorf("failed to unlock %s", file)
+		}
+		promptMu.Lock()
+		defer promptMu.Unlock()
+		passphrase, err := termio.ReadPassphrase(fmt.Sprintf("Enter passphrase for %s: ", file))
+		return []byte(passphrase), err
+	})
+}
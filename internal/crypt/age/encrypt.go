@@ -0,0 +1,28 @@
+package age
+
+import (
+	"bytes"
+	"fmt"
+
+	"filippo.io/age"
+)
+
+func (a *Age) Encrypt(plaintext []byte) ([]byte, error) {
+	if len(a.recipients) == 0 {
+		return nil, fmt.Errorf("no recipients configured")
+	}
+
+	buffer := &bytes.Buffer{}
+	w, err := age.Encrypt(buffer, a.recipients...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,42 @@
+package crypt
+
+import (
+	"github.com/torbenconto/gopwd/internal/crypt/age"
+	"github.com/torbenconto/gopwd/internal/crypt/gpg"
+	"github.com/torbenconto/gopwd/internal/crypt/openpgp"
+)
+
+var (
+	gpgFormat = Format{Extension: ".gpg", RecipientsFile: ".gpg-id"}
+	ageFormat = Format{Extension: ".age", RecipientsFile: ".age-recipients"}
+)
+
+func init() {
+	Register(gpg.Name, gpgFormat, func(config Config) (Backend, error) {
+		args := gpg.DefaultArgs()
+		if config.Batch {
+			args = append(args, "--batch", "--pinentry-mode=loopback")
+		}
+		if config.Passphrase != "" {
+			args = append(args, "--passphrase", config.Passphrase)
+		}
+
+		return gpg.NewGPG(config.Recipients, gpg.Config{Args: args}), nil
+	})
+
+	Register(openpgp.Name, gpgFormat, func(config Config) (Backend, error) {
+		return openpgp.NewOpenPGP(config.Recipients, openpgp.Config{
+			Keyring:    config.Keyring,
+			Passphrase: config.Passphrase,
+			Batch:      config.Batch,
+		})
+	})
+
+	Register(age.Name, ageFormat, func(config Config) (Backend, error) {
+		return age.NewAge(config.Recipients, age.Config{
+			Identities: config.Keyring,
+			Passphrase: config.Passphrase,
+			Batch:      config.Batch,
+		})
+	})
+}
@@ -0,0 +1,113 @@
+package crypt
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/torbenconto/gopwd/internal/io"
+)
+
+// DefaultBackend is used when .gopwd.yaml does not select a backend.
+const DefaultBackend = "gpg"
+
+// Backend encrypts and decrypts vault entries.
+type Backend interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+	Recipients() []string
+	Name() string
+}
+
+// Config holds the options shared by every backend.
+type Config struct {
+	// Recipients are the keys new entries are encrypted for.
+	Recipients []string
+	// Passphrase unlocks the secret key without prompting.
+	Passphrase string
+	// Batch disables interactive prompts, for use by the API server.
+	Batch bool
+	// Keyring locates the key material of backends that do not use an external agent.
+	Keyring string
+}
+
+// Factory creates a backend from a config.
+type Factory func(config Config) (Backend, error)
+
+// Format describes how a backend lays out a vault on disk.
+type Format struct {
+	// Extension is appended to service names to get entry file names.
+	Extension string
+	// RecipientsFile lists the recipients of a vault, one per line.
+	RecipientsFile string
+}
+
+type registration struct {
+	format  Format
+	factory Factory
+}
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]registration{}
+)
+
+// Register makes a backend available under name. It panics if name is already taken.
+func Register(name string, format Format, factory Factory) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	if _, ok := backends[name]; ok {
+		panic("crypt: backend registered twice: " + name)
+	}
+	backends[name] = registration{format: format, factory: factory}
+	io.RegisterExtension(format.Extension)
+}
+
+func lookup(name string) (registration, error) {
+	if name == "" {
+		name = DefaultBackend
+	}
+
+	backendsMu.RLock()
+	reg, ok := backends[name]
+	backendsMu.RUnlock()
+	if !ok {
+		return registration{}, fmt.Errorf("unknown encryption backend %q (available: %v)", name, Backends())
+	}
+
+	return reg, nil
+}
+
+// New returns the backend registered under name, or the default backend if name is empty.
+func New(name string, config Config) (Backend, error) {
+	reg, err := lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return reg.factory(config)
+}
+
+// FormatOf returns the on-disk format of the backend registered under name.
+func FormatOf(name string) (Format, error) {
+	reg, err := lookup(name)
+	if err != nil {
+		return Format{}, err
+	}
+
+	return reg.format, nil
+}
+
+// Backends returns the names of all registered backends.
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -8,7 +8,9 @@ import (
 
 func (g *GPG) Encrypt(plaintext []byte) ([]byte, error) {
 	args := append(g.Args(), "--encrypt")
-	args = append(args, "--recipient", g.ID())
+	for _, id := range g.IDs() {
+		args = append(args, "--recipient", id)
+	}
 
 	buffer := &bytes.Buffer{}
 
@@ -2,14 +2,23 @@ package gpg
 
 import (
 	"os"
+	"slices"
 )
 
+// Name identifies the exec-based GPG backend in .gopwd.yaml.
+const Name = "gpg"
+
 var (
 	defaultArgs = []string{"--quiet", "--yes", "--compress-algo=none", "--no-encrypt-to", "--no-auto-check-trustdb"}
 )
 
+// DefaultArgs returns a copy of the arguments passed to gpg when none are configured.
+func DefaultArgs() []string {
+	return append([]string(nil), defaultArgs...)
+}
+
 type GPG struct {
-	id         string
+	ids        []string
 	binaryPath string
 	args       []string
 }
@@ -19,9 +28,9 @@ type Config struct {
 	Args       []string
 }
 
-func NewGPG(id string, config Config) *GPG {
+func NewGPG(ids []string, config Config) *GPG {
 	gpg := &GPG{
-		id: id,
+		ids: ids,
 	}
 
 	// Set GPG_TTY environment variable
@@ -41,7 +50,7 @@ func NewGPG(id string, config Config) *GPG {
 
 	// Set config values
 	gpg.binaryPath = config.BinaryPath
-	gpg.args = config.Args
+	gpg.args = slices.Clip(config.Args)
 
 	return gpg
 }
@@ -50,10 +59,18 @@ func (g *GPG) Binary() string {
 	return g.binaryPath
 }
 
-func (g *GPG) ID() string {
-	return g.id
+func (g *GPG) IDs() []string {
+	return g.ids
 }
 
 func (g *GPG) Args() []string {
 	return g.args
 }
+
+func (g *GPG) Name() string {
+	return Name
+}
+
+func (g *GPG) Recipients() []string {
+	return g.ids
+}
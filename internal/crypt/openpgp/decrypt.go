@@ -0,0 +1,62 @@
+package openpgp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	pgp "github.com/ProtonMail/go-crypto/openpgp"
+
+	"github.com/torbenconto/gopwd/internal/termio"
+)
+
+// maxPassphraseAttempts limits how often a wrong passphrase is asked for again.
+const maxPassphraseAttempts = 3
+
+func (o *OpenPGP) Decrypt(ciphertext []byte) ([]byte, error) {
+	o.unlockMu.Lock()
+	md, err := pgp.ReadMessage(bytes.NewReader(ciphertext), o.keyring, o.prompt(), packetConfig)
+	o.unlockMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(md.UnverifiedBody)
+}
+
+// prompt unlocks the secret keys offered by ReadMessage, first with the configured
+// passphrase and then by asking on the terminal.
+func (o *OpenPGP) prompt() pgp.PromptFunction {
+	attempts := 0
+
+	return func(keys []pgp.Key, symmetric bool) ([]byte, error) {
+		if symmetric {
+			return nil, fmt.Errorf("symmetrically encrypted entries are not supported")
+		}
+
+		var passphrase string
+		switch {
+		case attempts == 0 && o.passphrase != "":
+			passphrase = o.passphrase
+		case o.batch || attempts >= maxPassphraseAttempts:
+			return nil, fmt.Errorf("failed to unlock secret key")
+		default:
+			var err error
+			passphrase, err = termio.ReadPassphrase(fmt.Sprintf("Enter passphrase for key %s: ", keys[0].PublicKey.KeyIdString()))
+			if err != nil {
+				return nil, err
+			}
+		}
+		attempts++
+
+		for _, key := range keys {
+			if key.PrivateKey != nil && key.PrivateKey.Encrypted {
+				if err := key.PrivateKey.Decrypt([]byte(passphrase)); err == nil {
+					return nil, nil
+				}
+			}
+		}
+
+		return nil, nil
+	}
+}
@@ -0,0 +1,37 @@
+package openpgp
+
+import (
+	"bytes"
+	"fmt"
+
+	pgp "github.com/ProtonMail/go-crypto/openpgp"
+)
+
+func (o *OpenPGP) Encrypt(plaintext []byte) ([]byte, error) {
+	if len(o.ids) == 0 {
+		return nil, fmt.Errorf("no recipients configured")
+	}
+
+	to := make([]*pgp.Entity, 0, len(o.ids))
+	for _, id := range o.ids {
+		entity, err := FindEntity(o.keyring, id)
+		if err != nil {
+			return nil, err
+		}
+		to = append(to, entity)
+	}
+
+	buffer := &bytes.Buffer{}
+	w, err := pgp.Encrypt(buffer, to, nil, nil, packetConfig)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
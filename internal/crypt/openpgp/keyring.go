@@ -0,0 +1,222 @@
+package openpgp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pgp "github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+
+	"github.com/torbenconto/gopwd/internal/io"
+)
+
+// Files read from a GnuPG home directory, in order. pubring.kbx is used by GnuPG 2.1 and
+// later, the others by older versions. Secret keys held by gpg-agent in private-keys-v1.d
+// cannot be read; export them with "gpg --export-secret-keys > secret.gpg" instead.
+var gnupgHomeFiles = []string{"pubring.kbx", "pubring.gpg", "secring.gpg", "secret.gpg", "secret.asc"}
+
+// DefaultKeyring returns $GNUPGHOME, falling back to ~/.gnupg.
+func DefaultKeyring() string {
+	if home := os.Getenv("GNUPGHOME"); home != "" {
+		return home
+	}
+	return filepath.Join(io.GetHomeDir(), ".gnupg")
+}
+
+// LoadKeyring reads every key found at the given locations. A public key that also has a
+// secret counterpart is returned once, with the secret key attached.
+func LoadKeyring(locations string) (pgp.EntityList, error) {
+	var entities pgp.EntityList
+
+	for _, location := range filepath.SplitList(locations) {
+		info, err := os.Stat(location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open keyring: %v", err)
+		}
+
+		if !info.IsDir() {
+			el, err := readKeyringFile(location)
+			if err != nil {
+				return nil, err
+			}
+			entities = append(entities, el...)
+			continue
+		}
+
+		for _, name := range gnupgHomeFiles {
+			file := filepath.Join(location, name)
+			if !io.Exists(file) {
+				continue
+			}
+			el, err := readKeyringFile(file)
+			if err != nil {
+				return nil, err
+			}
+			entities = append(entities, el...)
+		}
+	}
+
+	return dedupe(entities), nil
+}
+
+func readKeyringFile(file string) (pgp.EntityList, error) {
+	data, err := io.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring %s: %v", file, err)
+	}
+
+	var el pgp.EntityList
+	switch {
+	case isKeybox(data):
+		el, err = readKeybox(data)
+	case bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN")):
+		el, err = readArmored(data)
+	default:
+		el, err = pgp.ReadKeyRing(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse keyring %s: %v", file, err)
+	}
+
+	return el, nil
+}
+
+// readArmored reads every armored block in data, so a file holding both an exported public
+// and secret key works.
+func readArmored(data []byte) (pgp.EntityList, error) {
+	var el pgp.EntityList
+	r := bytes.NewReader(data)
+	for {
+		block, err := armor.Decode(r)
+		if err != nil {
+			if len(el) > 0 {
+				return el, nil
+			}
+			return nil, err
+		}
+		entities, err := pgp.ReadKeyRing(block.Body)
+		if err != nil {
+			return nil, err
+		}
+		el = append(el, entities...)
+	}
+}
+
+// isKeybox reports whether data starts with the header blob of a GnuPG keybox file.
+func isKeybox(data []byte) bool {
+	return len(data) >= 12 && data[4] == 1 && string(data[8:12]) == "KBXf"
+}
+
+// readKeybox extracts the OpenPGP keyblocks stored in a keybox (pubring.kbx) file.
+func readKeybox(data []byte) (pgp.EntityList, error) {
+	const (
+		blobOpenPGP = 2
+		headerLen   = 16
+	)
+
+	var el pgp.EntityList
+	for len(data) >= headerLen {
+		blobLen := int(binary.BigEndian.Uint32(data[0:4]))
+		if blobLen < headerLen || blobLen > len(data) {
+			return nil, fmt.Errorf("corrupt keybox blob")
+		}
+		blob := data[:blobLen]
+		data = data[blobLen:]
+
+		if blob[4] != blobOpenPGP {
+			continue
+		}
+
+		offset := int(binary.BigEndian.Uint32(blob[8:12]))
+		length := int(binary.BigEndian.Uint32(blob[12:16]))
+		if offset+length > len(blob) {
+			return nil, fmt.Errorf("corrupt keybox blob")
+		}
+
+		entities, err := pgp.ReadKeyRing(bytes.NewReader(blob[offset : offset+length]))
+		if err != nil {
+			return nil, err
+		}
+		el = append(el, entities...)
+	}
+
+	return el, nil
+}
+
+func dedupe(entities pgp.EntityList) pgp.EntityList {
+	var out pgp.EntityList
+	index := map[string]int{}
+	for _, e := range entities {
+		fpr := hex.EncodeToString(e.PrimaryKey.Fingerprint)
+		if i, ok := index[fpr]; ok {
+			if out[i].PrivateKey == nil && e.PrivateKey != nil {
+				out[i] = e
+			}
+			continue
+		}
+		index[fpr] = len(out)
+		out = append(out, e)
+	}
+	return out
+}
+
+// FindEntity returns the key matching id, using the same rules as gpg --recipient: a key ID
+// or fingerprint (with an optional 0x prefix), an email address, or part of a user ID.
+func FindEntity(keyring pgp.EntityList, id string) (*pgp.Entity, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, fmt.Errorf("empty recipient")
+	}
+
+	hexID := strings.ToUpper(strings.TrimPrefix(strings.TrimPrefix(id, "0x"), "0X"))
+	if isHex(hexID) && (len(hexID) == 8 || len(hexID) == 16 || len(hexID) == 40 || len(hexID) == 64) {
+		for _, e := range keyring {
+			for _, fpr := range entityFingerprints(e) {
+				if strings.HasSuffix(fpr, hexID) {
+					return e, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("no public key found for %s", id)
+	}
+
+	email := strings.ToLower(strings.Trim(id, "<>"))
+	for _, e := range keyring {
+		for _, identity := range e.Identities {
+			if strings.ToLower(identity.UserId.Email) == email {
+				return e, nil
+			}
+		}
+	}
+	for _, e := range keyring {
+		for name := range e.Identities {
+			if strings.Contains(strings.ToLower(name), strings.ToLower(id)) {
+				return e, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no public key found for %s", id)
+}
+
+func entityFingerprints(e *pgp.Entity) []string {
+	fprs := []string{strings.ToUpper(hex.EncodeToString(e.PrimaryKey.Fingerprint))}
+	for _, sub := range e.Subkeys {
+		fprs = append(fprs, strings.ToUpper(hex.EncodeToString(sub.PublicKey.Fingerprint)))
+	}
+	return fprs
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789ABCDEF", c) {
+			return false
+		}
+	}
+	return s != ""
+}
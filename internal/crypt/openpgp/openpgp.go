@@ -0,0 +1,68 @@
+package openpgp
+
+import (
+	"sync"
+
+	pgp "github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// Name identifies the native OpenPGP backend in .gopwd.yaml.
+const Name = "openpgp"
+
+// packetConfig matches the defaults gopwd passes to gpg (--compress-algo=none), so
+// files written by either backend can be read by the other.
+var packetConfig = &packet.Config{
+	DefaultCompressionAlgo: packet.CompressionNone,
+}
+
+// OpenPGP is a pure Go replacement for the gpg binary.
+type OpenPGP struct {
+	ids        []string
+	keyring    pgp.EntityList
+	passphrase string
+	batch      bool
+
+	// unlockMu serializes secret key unlocking, which mutates the shared keyring.
+	unlockMu sync.Mutex
+}
+
+type Config struct {
+	// Keyring is an exported keyring file or a GnuPG home directory. Several
+	// locations can be given, separated by the OS path list separator.
+	Keyring    string
+	Passphrase string
+	// Batch disables the passphrase prompt.
+	Batch bool
+}
+
+func NewOpenPGP(ids []string, config Config) (*OpenPGP, error) {
+	if config.Keyring == "" {
+		config.Keyring = DefaultKeyring()
+	}
+
+	keyring, err := LoadKeyring(config.Keyring)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OpenPGP{
+		ids:        ids,
+		keyring:    keyring,
+		passphrase: config.Passphrase,
+		batch:      config.Batch,
+	}, nil
+}
+
+func (o *OpenPGP) Name() string {
+	return Name
+}
+
+func (o *OpenPGP) Recipients() []string {
+	return o.ids
+}
+
+// Keyring returns the keys loaded by the backend.
+func (o *OpenPGP) Keyring() pgp.EntityList {
+	return o.keyring
+}
@@ -0,0 +1,46 @@
+package openpgp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	pgp "github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// EncryptedKeyIDs lists the key IDs a message is encrypted to by reading its public-key
+// encrypted session key packets. Nothing is decrypted. Hidden recipients have key ID 0.
+func EncryptedKeyIDs(ciphertext []byte) ([]uint64, error) {
+	var ids []uint64
+
+	packets := packet.NewReader(bytes.NewReader(ciphertext))
+	for {
+		p, err := packets.Next()
+		if errors.Is(err, io.EOF) {
+			return ids, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch p := p.(type) {
+		case *packet.EncryptedKey:
+			ids = append(ids, p.KeyId)
+		case *packet.SymmetricKeyEncrypted:
+			continue
+		default:
+			// The session keys always come first, so the rest is encrypted data
+			return ids, nil
+		}
+	}
+}
+
+// EntityKeyIDs returns the key IDs of an entity's primary key and subkeys.
+func EntityKeyIDs(e *pgp.Entity) []uint64 {
+	ids := []uint64{e.PrimaryKey.KeyId}
+	for _, sub := range e.Subkeys {
+		ids = append(ids, sub.PublicKey.KeyId)
+	}
+	return ids
+}
@@ -0,0 +1,180 @@
+package doctor
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	pgp "github.com/ProtonMail/go-crypto/openpgp"
+
+	"github.com/torbenconto/gopwd/internal/crypt/age"
+	"github.com/torbenconto/gopwd/internal/crypt/openpgp"
+	"github.com/torbenconto/gopwd/internal/io"
+	"github.com/torbenconto/gopwd/internal/util"
+)
+
+// RecipientsReport lists the entries whose recipients differ from their effective .gpg-id.
+type RecipientsReport struct {
+	Checked  int               `json:"checked"`
+	Stale    []EntryRecipients `json:"stale"`
+	Warnings []string          `json:"warnings,omitempty"`
+}
+
+// EntryRecipients describes one entry that is out of date.
+type EntryRecipients struct {
+	Service        string   `json:"service"`
+	RecipientsFile string   `json:"recipients_file"`
+	Missing        []string `json:"missing,omitempty"`
+	Extra          []string `json:"extra,omitempty"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// CheckRecipients compares the key IDs each entry below subtree is encrypted to with the
+// keys of the recipients in its nearest .gpg-id. Entries are not decrypted. keyring is
+// used to resolve .gpg-id entries to key IDs.
+func CheckRecipients(vaultPath, subtree string, keyring pgp.EntityList) (*RecipientsReport, error) {
+	if util.BackendName(vaultPath) == age.Name {
+		return nil, fmt.Errorf("checking recipients is only supported for OpenPGP vaults")
+	}
+
+	services, err := util.ListServicesUnder(vaultPath, subtree)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RecipientsReport{Stale: []EntryRecipients{}}
+	expected := map[string]*recipients{}
+	warned := map[string]bool{}
+
+	for _, service := range services {
+		report.Checked++
+
+		file, err := util.RecipientsFile(vaultPath, service)
+		if err != nil {
+			return nil, err
+		}
+		rel, _ := filepath.Rel(vaultPath, file)
+		entry := EntryRecipients{Service: service, RecipientsFile: filepath.ToSlash(rel)}
+
+		want, ok := expected[file]
+		if !ok {
+			ids, err := util.ReadGPGID(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %v", rel, err)
+			}
+			want = &recipients{}
+			for _, id := range ids {
+				entity, err := openpgp.FindEntity(keyring, id)
+				if err != nil {
+					if !warned[id] {
+						report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %v", rel, err))
+						warned[id] = true
+					}
+					// Still required, entries can only be checked against the ID itself
+					want.unresolved = append(want.unresolved, id)
+					continue
+				}
+				want.entities = append(want.entities, entity)
+			}
+			expected[file] = want
+		}
+
+		ciphertext, err := io.ReadFile(util.ServicePath(vaultPath, service))
+		if err == nil {
+			var keyIDs []uint64
+			keyIDs, err = openpgp.EncryptedKeyIDs(ciphertext)
+			if err == nil {
+				entry.Missing, entry.Extra = compareRecipients(want, keyIDs, keyring)
+			}
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+
+		if entry.Error != "" || len(entry.Missing) > 0 || len(entry.Extra) > 0 {
+			report.Stale = append(report.Stale, entry)
+		}
+	}
+
+	return report, nil
+}
+
+// recipients are the recipients listed in a .gpg-id. IDs that are not in the keyring are kept
+// as they are written.
+type recipients struct {
+	entities   []*pgp.Entity
+	unresolved []string
+}
+
+// compareRecipients returns the expected recipients none of whose keys were used, and the
+// key IDs that do not belong to any expected recipient. Unresolved recipients are only found
+// if they are written as a key ID or fingerprint that was used.
+func compareRecipients(expected *recipients, keyIDs []uint64, keyring pgp.EntityList) (missing, extra []string) {
+	used := map[uint64]bool{}
+	for _, id := range keyIDs {
+		used[id] = true
+	}
+
+	matched := map[uint64]bool{}
+	for _, raw := range expected.unresolved {
+		id, ok := parseKeyID(raw)
+		if ok && used[id] {
+			matched[id] = true
+			continue
+		}
+		missing = append(missing, raw+" (not in keyring)")
+	}
+
+	for _, entity := range expected.entities {
+		found := false
+		for _, id := range openpgp.EntityKeyIDs(entity) {
+			if used[id] {
+				found = true
+				matched[id] = true
+			}
+		}
+		if !found {
+			missing = append(missing, describeKey(entity.PrimaryKey.KeyId, keyring))
+		}
+	}
+
+	for _, id := range keyIDs {
+		if !matched[id] {
+			extra = append(extra, describeKey(id, keyring))
+		}
+	}
+	slices.Sort(extra)
+
+	return missing, extra
+}
+
+// parseKeyID returns the key ID of a .gpg-id entry written as a hex key ID or fingerprint.
+func parseKeyID(raw string) (uint64, bool) {
+	hex := strings.TrimPrefix(strings.ToUpper(strings.ReplaceAll(raw, " ", "")), "0X")
+	if len(hex) != 16 && len(hex) != 40 {
+		return 0, false
+	}
+
+	id, err := strconv.ParseUint(hex[len(hex)-16:], 16, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// describeKey formats a key ID with the primary user ID of its key, if known.
+func describeKey(id uint64, keyring pgp.EntityList) string {
+	if id == 0 {
+		return "hidden recipient"
+	}
+
+	keyID := fmt.Sprintf("%016X", id)
+	for _, key := range keyring.KeysById(id) {
+		if identity := key.Entity.PrimaryIdentity(); identity != nil {
+			return keyID + " (" + strings.TrimSpace(identity.Name) + ")"
+		}
+	}
+	return keyID
+}
@@ -0,0 +1,155 @@
+package entry
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PasswordField is the name under which Get returns the first line of an entry.
+const PasswordField = "password"
+
+// fieldPattern matches "key: value" lines. The colon must be followed by a space or the end
+// of the line, so URLs such as https://example.com are not mistaken for fields.
+var fieldPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9 _.-]*?)\s*:(?:\s+(.*))?$`)
+
+// Entry is a decrypted vault entry in the pass format: the password on the first line,
+// followed by "key: value" fields and free-form notes.
+type Entry struct {
+	lines []string
+	// newline and finalNewline record how the content was laid out, so Bytes writes untouched
+	// lines back as they were.
+	newline      string
+	finalNewline bool
+}
+
+// Field is a "key: value" line of an entry.
+type Field struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func Parse(content []byte) *Entry {
+	newline := "\n"
+	if strings.Contains(string(content), "\r\n") {
+		newline = "\r\n"
+	}
+
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	finalNewline := strings.HasSuffix(text, "\n")
+	text = strings.TrimSuffix(text, "\n")
+
+	return &Entry{
+		lines:        strings.Split(text, "\n"),
+		newline:      newline,
+		finalNewline: finalNewline,
+	}
+}
+
+// Password returns the first line of the entry.
+func (e *Entry) Password() string {
+	return e.lines[0]
+}
+
+// SetPassword replaces the first line of the entry and keeps the rest.
+func (e *Entry) SetPassword(password string) {
+	e.lines[0] = password
+}
+
+// Fields returns the "key: value" lines after the password, in order.
+func (e *Entry) Fields() []Field {
+	var fields []Field
+	for _, line := range e.lines[1:] {
+		if field, ok := parseField(line); ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// FieldMap returns the fields keyed by name. When a key appears twice the first value wins.
+func (e *Entry) FieldMap() map[string]string {
+	fields := map[string]string{}
+	for _, field := range e.Fields() {
+		if _, ok := fields[field.Key]; !ok {
+			fields[field.Key] = field.Value
+		}
+	}
+	return fields
+}
+
+// Notes returns the lines after the password that are not fields.
+func (e *Entry) Notes() []string {
+	var notes []string
+	for _, line := range e.lines[1:] {
+		if _, ok := parseField(line); !ok && strings.TrimSpace(line) != "" {
+			notes = append(notes, line)
+		}
+	}
+	return notes
+}
+
+// Get returns the value of the field called key, ignoring case. "password" returns the
+// first line unless the entry has a field of that name.
+func (e *Entry) Get(key string) (string, bool) {
+	for _, field := range e.Fields() {
+		if strings.EqualFold(field.Key, key) {
+			return field.Value, true
+		}
+	}
+
+	if strings.EqualFold(key, PasswordField) {
+		return e.Password(), true
+	}
+
+	return "", false
+}
+
+// Set replaces the value of the field called key, or appends the field if it is missing.
+func (e *Entry) Set(key, value string) {
+	for i, line := range e.lines[1:] {
+		if field, ok := parseField(line); ok && strings.EqualFold(field.Key, key) {
+			e.lines[i+1] = field.Key + ": " + value
+			return
+		}
+	}
+
+	e.lines = append(e.lines, key+": "+value)
+}
+
+// SetLine replaces line i of the entry, appending a line if i is past the end.
+func (e *Entry) SetLine(i int, line string) {
+	if i < len(e.lines) {
+		e.lines[i] = line
+		return
+	}
+	e.lines = append(e.lines, line)
+}
+
+// Lines returns every line of the entry.
+func (e *Entry) Lines() []string {
+	return e.lines
+}
+
+// Bytes returns the entry in the form it is stored in, with the line endings and final newline
+// it was parsed with.
+func (e *Entry) Bytes() []byte {
+	newline := e.newline
+	if newline == "" {
+		newline = "\n"
+	}
+
+	text := strings.Join(e.lines, newline)
+	if e.finalNewline {
+		text += newline
+	}
+	return []byte(text)
+}
+
+func parseField(line string) (Field, bool) {
+	match := fieldPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return Field{}, false
+	}
+
+	return Field{Key: match[1], Value: strings.TrimSpace(match[2])}, true
+}
@@ -0,0 +1,26 @@
+package entry
+
+import "testing"
+
+func TestBytesKeepsLayout(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"lf", "old\nusername: bob\n", "new\nusername: bob\n"},
+		{"crlf", "old\r\nusername: bob\r\nnotes\r\n", "new\r\nusername: bob\r\nnotes\r\n"},
+		{"no final newline", "old\nusername: bob", "new\nusername: bob"},
+		{"password only", "old", "new"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Parse([]byte(tt.content))
+			e.SetPassword("new")
+			if got := string(e.Bytes()); got != tt.want {
+				t.Errorf("Bytes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package entry
+
+import (
+	"strings"
+	"unicode"
+)
+
+// secretKeys are field names whose values are treated as secrets.
+var secretKeys = []string{"password", "passwd", "pass", "pin", "secret", "token", "key", "otp", "totp", "recovery"}
+
+// IsSecretLine reports whether line i of the entry looks like it holds a secret: the password
+// itself, an otpauth:// URI, a field named like a secret or a value that looks randomly generated.
+func (e *Entry) IsSecretLine(i int) bool {
+	if i == 0 {
+		return true
+	}
+
+	line := strings.TrimSpace(e.lines[i])
+	if strings.HasPrefix(line, "otpauth://") {
+		return true
+	}
+
+	if field, ok := parseField(line); ok {
+		key := strings.ToLower(field.Key)
+		for _, secret := range secretKeys {
+			if strings.Contains(key, secret) {
+				return true
+			}
+		}
+		return looksGenerated(field.Value)
+	}
+
+	return looksGenerated(line)
+}
+
+// MaskLine returns line i of the entry with its secret replaced by asterisks. Field names are kept.
+func (e *Entry) MaskLine(i int) string {
+	const mask = "********"
+
+	if i > 0 {
+		if field, ok := parseField(e.lines[i]); ok && field.Value != "" {
+			return field.Key + ": " + mask
+		}
+	}
+
+	return mask
+}
+
+// looksGenerated reports whether s is a single word of at least 8 characters mixing three or
+// more character classes, as generated passwords do.
+func looksGenerated(s string) bool {
+	if len(s) < 8 || strings.ContainsAny(s, " \t") || strings.Contains(s, "://") {
+		return false
+	}
+
+	var lower, upper, digit, other bool
+	for _, r := range s {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, other} {
+		if present {
+			classes++
+		}
+	}
+
+	return classes >= 3
+}
@@ -0,0 +1,53 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+)
+
+// writeCSV writes one row per record. Every field name used by any record gets a column after
+// the fixed ones, so the file can be opened as a spreadsheet.
+func writeCSV(w io.Writer, records []Record) error {
+	seen := map[string]bool{}
+	var extra []string
+	for _, record := range records {
+		for _, field := range record.Fields {
+			if !seen[field.Key] {
+				seen[field.Key] = true
+				extra = append(extra, field.Key)
+			}
+		}
+	}
+	sort.Strings(extra)
+
+	writer := csv.NewWriter(w)
+
+	header := append([]string{"folder", "name", "password", "username", "url", "otpauth", "notes"}, extra...)
+	err := writer.Write(header)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		row := []string{record.folder(), record.name(), record.Password, record.Username, record.URL, record.OTP, record.Notes}
+		for _, key := range extra {
+			value := ""
+			for _, field := range record.Fields {
+				if field.Key == key {
+					value = field.Value
+					break
+				}
+			}
+			row = append(row, value)
+		}
+
+		err = writer.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
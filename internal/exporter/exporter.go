@@ -0,0 +1,98 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/torbenconto/gopwd/internal/entry"
+	"github.com/torbenconto/gopwd/internal/otp"
+)
+
+// Record is a decrypted entry in the structured form written by the exporters.
+type Record struct {
+	Service  string        `json:"service"`
+	Password string        `json:"password"`
+	Username string        `json:"username,omitempty"`
+	URL      string        `json:"url,omitempty"`
+	OTP      string        `json:"otp,omitempty"`
+	Fields   []entry.Field `json:"fields,omitempty"`
+	Notes    string        `json:"notes,omitempty"`
+}
+
+// Writer writes records in an export format.
+type Writer func(w io.Writer, records []Record) error
+
+var writers = map[string]Writer{
+	"json":        writeJSON,
+	"csv":         writeCSV,
+	"keepass-xml": writeKeePassXML,
+}
+
+// Formats returns the names of the export formats.
+func Formats() []string {
+	names := make([]string, 0, len(writers))
+	for name := range writers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Write writes records to w in format.
+func Write(format string, w io.Writer, records []Record) error {
+	writer, ok := writers[format]
+	if !ok {
+		return fmt.Errorf("unknown export format %q (available: %v)", format, Formats())
+	}
+
+	return writer(w, records)
+}
+
+// NewRecord parses the decrypted content of service. The username and url fields and the
+// otpauth:// line get their own place in the record; other fields are kept in order.
+func NewRecord(service string, content []byte) Record {
+	parsed := entry.Parse(content)
+	record := Record{
+		Service:  service,
+		Password: parsed.Password(),
+	}
+
+	for _, field := range parsed.Fields() {
+		switch {
+		case record.Username == "" && strings.EqualFold(field.Key, "username"):
+			record.Username = field.Value
+		case record.URL == "" && strings.EqualFold(field.Key, "url"):
+			record.URL = field.Value
+		default:
+			record.Fields = append(record.Fields, field)
+		}
+	}
+
+	var notes []string
+	for _, line := range parsed.Notes() {
+		if record.OTP == "" && strings.HasPrefix(strings.TrimSpace(line), otp.Scheme) {
+			record.OTP = strings.TrimSpace(line)
+			continue
+		}
+		notes = append(notes, line)
+	}
+	record.Notes = strings.Join(notes, "\n")
+
+	return record
+}
+
+// folder returns the directory of the record's service, or "" at the vault root.
+func (r Record) folder() string {
+	if dir := path.Dir(r.Service); dir != "." {
+		return dir
+	}
+	return ""
+}
+
+// name returns the last path segment of the record's service.
+func (r Record) name() string {
+	return path.Base(r.Service)
+}
@@ -0,0 +1,13 @@
+package exporter
+
+import (
+	"encoding/json"
+	"io"
+)
+
+func writeJSON(w io.Writer, records []Record) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(records)
+}
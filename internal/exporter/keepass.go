@@ -0,0 +1,107 @@
+package exporter
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+type keePassFile struct {
+	XMLName xml.Name `xml:"KeePassFile"`
+	Meta    struct {
+		Generator    string `xml:"Generator"`
+		DatabaseName string `xml:"DatabaseName"`
+	} `xml:"Meta"`
+	Root struct {
+		Group *keePassGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+type keePassGroup struct {
+	Name    string          `xml:"Name"`
+	Entries []keePassEntry  `xml:"Entry"`
+	Groups  []*keePassGroup `xml:"Group"`
+}
+
+type keePassEntry struct {
+	Strings []keePassString `xml:"String"`
+}
+
+type keePassString struct {
+	Key   string `xml:"Key"`
+	Value struct {
+		Text            string `xml:",chardata"`
+		ProtectInMemory string `xml:"ProtectInMemory,attr,omitempty"`
+	} `xml:"Value"`
+}
+
+// writeKeePassXML writes a KeePass 2 XML file that KeePass and KeePassXC can import. Directories
+// become groups below a root group named gopwd.
+func writeKeePassXML(w io.Writer, records []Record) error {
+	var file keePassFile
+	file.Meta.Generator = "gopwd"
+	file.Meta.DatabaseName = "gopwd"
+	root := &keePassGroup{Name: "gopwd"}
+	file.Root.Group = root
+
+	for _, record := range records {
+		group := root
+		if folder := record.folder(); folder != "" {
+			for _, name := range strings.Split(folder, "/") {
+				group = group.child(name)
+			}
+		}
+
+		e := keePassEntry{}
+		e.add("Title", record.name(), false)
+		e.add("UserName", record.Username, false)
+		e.add("Password", record.Password, true)
+		e.add("URL", record.URL, false)
+		e.add("Notes", record.Notes, false)
+		if record.OTP != "" {
+			e.add("otp", record.OTP, true)
+		}
+		for _, field := range record.Fields {
+			e.add(field.Key, field.Value, false)
+		}
+
+		group.Entries = append(group.Entries, e)
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "\t")
+	err = encoder.Encode(file)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// child returns the subgroup called name, creating it if needed.
+func (g *keePassGroup) child(name string) *keePassGroup {
+	for _, group := range g.Groups {
+		if group.Name == name {
+			return group
+		}
+	}
+
+	group := &keePassGroup{Name: name}
+	g.Groups = append(g.Groups, group)
+	return group
+}
+
+func (e *keePassEntry) add(key, value string, protected bool) {
+	s := keePassString{Key: key}
+	s.Value.Text = value
+	if protected {
+		s.Value.ProtectInMemory = "True"
+	}
+	e.Strings = append(e.Strings, s)
+}
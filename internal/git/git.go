@@ -0,0 +1,97 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/torbenconto/gopwd/internal/io"
+)
+
+// Git runs git inside a vault.
+type Git struct {
+	dir        string
+	binaryPath string
+}
+
+func NewGit(dir string) *Git {
+	return &Git{
+		dir:        dir,
+		binaryPath: "git",
+	}
+}
+
+// Enabled reports whether the vault is a git repository.
+func (g *Git) Enabled() bool {
+	return io.Exists(filepath.Join(g.dir, ".git"))
+}
+
+// Run runs git with args in the vault, connected to the terminal.
+func (g *Git) Run(args ...string) error {
+	cmd := exec.Command(g.binaryPath, append([]string{"-C", g.dir}, args...)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+// output runs git with args in the vault and returns its output.
+func (g *Git) output(args ...string) (string, error) {
+	stderr := &bytes.Buffer{}
+
+	cmd := exec.Command(g.binaryPath, append([]string{"-C", g.dir}, args...)...)
+	cmd.Stderr = stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return string(out), nil
+}
+
+// Init turns the vault into a git repository and commits its current contents.
+func (g *Git) Init() error {
+	if g.Enabled() {
+		return fmt.Errorf("git is already enabled for %s", g.dir)
+	}
+
+	if _, err := g.output("init", "-q"); err != nil {
+		return err
+	}
+
+	return g.Commit("Initialize gopwd vault")
+}
+
+// Commit stages every change in the vault and commits it with message. Nothing happens
+// when there is nothing to commit.
+func (g *Git) Commit(message string) error {
+	if _, err := g.output("add", "-A"); err != nil {
+		return err
+	}
+
+	status, err := g.output("status", "--porcelain")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(status) == "" {
+		return nil
+	}
+
+	_, err = g.output("commit", "-q", "-m", message)
+	return err
+}
+
+// AutoCommit commits every change in the vault if git is enabled for it.
+func AutoCommit(vaultPath, message string) error {
+	g := NewGit(vaultPath)
+	if !g.Enabled() {
+		return nil
+	}
+
+	return g.Commit(message)
+}
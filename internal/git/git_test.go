@@ -0,0 +1,112 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupGit isolates git from the user's configuration and gives it an identity.
+func setupGit(t *testing.T) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "init.defaultBranch")
+	t.Setenv("GIT_CONFIG_VALUE_0", "main")
+	t.Setenv("GIT_AUTHOR_NAME", "gopwd")
+	t.Setenv("GIT_AUTHOR_EMAIL", "gopwd@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "gopwd")
+	t.Setenv("GIT_COMMITTER_EMAIL", "gopwd@example.com")
+}
+
+func writeEntry(t *testing.T, vaultPath, service, content string) {
+	t.Helper()
+
+	err := os.WriteFile(filepath.Join(vaultPath, service+".gpg"), []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAutoCommitPushPull(t *testing.T) {
+	setupGit(t)
+
+	remote := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v: %s", err, out)
+	}
+
+	// Without git, AutoCommit does nothing
+	vault := t.TempDir()
+	writeEntry(t, vault, "github", "first")
+	if err := AutoCommit(vault, "Add github"); err != nil {
+		t.Fatalf("AutoCommit without git error: %v", err)
+	}
+
+	g := NewGit(vault)
+	if err := g.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+	if err := g.Init(); err == nil {
+		t.Errorf("second Init succeeded, want an error")
+	}
+	if err := g.Run("remote", "add", "origin", remote); err != nil {
+		t.Fatalf("remote add error: %v", err)
+	}
+	if err := g.Run("push", "-q", "-u", "origin", "HEAD"); err != nil {
+		t.Fatalf("push error: %v", err)
+	}
+
+	// A second machine clones the vault from the remote
+	clone := filepath.Join(t.TempDir(), "vault")
+	if out, err := exec.Command("git", "clone", "-q", remote, clone).CombinedOutput(); err != nil {
+		t.Fatalf("git clone: %v: %s", err, out)
+	}
+	if data, err := os.ReadFile(filepath.Join(clone, "github.gpg")); err != nil || string(data) != "first" {
+		t.Fatalf("cloned entry = %q, %v, want the committed entry", data, err)
+	}
+
+	// Changes are committed automatically, pushed from the clone and pulled into the vault
+	writeEntry(t, clone, "github", "second")
+	writeEntry(t, clone, "gitlab", "new")
+	if err := AutoCommit(clone, "Update github"); err != nil {
+		t.Fatalf("AutoCommit error: %v", err)
+	}
+	if err := NewGit(clone).Run("push", "-q"); err != nil {
+		t.Fatalf("push error: %v", err)
+	}
+	if err := g.Run("pull", "-q", "--rebase"); err != nil {
+		t.Fatalf("pull error: %v", err)
+	}
+
+	for service, want := range map[string]string{"github": "second", "gitlab": "new"} {
+		data, err := os.ReadFile(filepath.Join(vault, service+".gpg"))
+		if err != nil || string(data) != want {
+			t.Errorf("pulled %s = %q, %v, want %q", service, data, err, want)
+		}
+	}
+
+	log, err := g.output("log", "--format=%s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Update github\nInitialize gopwd vault\n"; log != want {
+		t.Errorf("log = %q, want %q", log, want)
+	}
+
+	// Nothing to commit is not an error and adds no commit
+	if err := AutoCommit(vault, "Nothing"); err != nil {
+		t.Fatalf("AutoCommit without changes error: %v", err)
+	}
+	if log, _ := g.output("log", "--format=%s"); strings.Contains(log, "Nothing") {
+		t.Errorf("AutoCommit without changes made a commit")
+	}
+}
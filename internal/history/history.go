@@ -0,0 +1,135 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/torbenconto/gopwd/internal/io"
+)
+
+// Dir is the hidden directory inside the vault that keeps earlier ciphertexts of entries.
+const Dir = ".history"
+
+// Version is an earlier ciphertext of an entry. Versions are numbered from 1, oldest first.
+type Version struct {
+	Number int `json:"number"`
+	// Time is when the version was written.
+	Time time.Time `json:"time"`
+	Path string    `json:"-"`
+
+	replaced int64
+}
+
+func serviceDir(vaultPath, service string) string {
+	return filepath.Join(vaultPath, Dir, filepath.FromSlash(service))
+}
+
+// Save keeps a copy of the entry at servicePath before it is overwritten. Nothing is saved
+// if the entry does not exist yet.
+func Save(vaultPath, service, servicePath string) error {
+	info, err := io.Stat(servicePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	dir := serviceDir(vaultPath, service)
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create history directory: %v", err)
+	}
+
+	// Name versions after the time they were replaced, so they sort by age
+	version := filepath.Join(dir, strconv.FormatInt(time.Now().UnixNano(), 10)+filepath.Ext(servicePath))
+	err = io.CopyFile(servicePath, version)
+	if err != nil {
+		return fmt.Errorf("failed to save previous version: %v", err)
+	}
+
+	return os.Chtimes(version, info.ModTime(), info.ModTime())
+}
+
+// List returns the saved versions of service, oldest first.
+func List(vaultPath, service string) ([]Version, error) {
+	entries, err := os.ReadDir(serviceDir(vaultPath, service))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []Version
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		replaced, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, Version{
+			Time:     info.ModTime(),
+			Path:     filepath.Join(serviceDir(vaultPath, service), entry.Name()),
+			replaced: replaced,
+		})
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].replaced < versions[j].replaced
+	})
+	for i := range versions {
+		versions[i].Number = i + 1
+	}
+
+	return versions, nil
+}
+
+// Replaced returns when the version was overwritten by the next one.
+func (v Version) Replaced() time.Time {
+	return time.Unix(0, v.replaced)
+}
+
+// Get returns version n of service.
+func Get(vaultPath, service string, n int) (Version, error) {
+	versions, err := List(vaultPath, service)
+	if err != nil {
+		return Version{}, err
+	}
+
+	if n < 1 || n > len(versions) {
+		return Version{}, fmt.Errorf("service %s has no version %d (%d saved)", service, n, len(versions))
+	}
+
+	return versions[n-1], nil
+}
+
+// Rename moves the saved versions of service along with the entry.
+func Rename(vaultPath, service, newService string) error {
+	dir := serviceDir(vaultPath, service)
+	if !io.Exists(dir) {
+		return nil
+	}
+
+	newDir := serviceDir(vaultPath, newService)
+	err := os.MkdirAll(filepath.Dir(newDir), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(dir, newDir)
+}
@@ -0,0 +1,103 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/torbenconto/gopwd/internal/entry"
+)
+
+var errEncryptedExport = errors.New("export is encrypted, export it unencrypted instead")
+
+// bitwardenExport is the unencrypted JSON export of a Bitwarden vault or organisation.
+type bitwardenExport struct {
+	Encrypted bool `json:"encrypted"`
+	Folders   []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Collections []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"collections"`
+	Items []struct {
+		FolderID      string   `json:"folderId"`
+		CollectionIDs []string `json:"collectionIds"`
+		Name          string   `json:"name"`
+		Notes         string   `json:"notes"`
+		Fields        []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"fields"`
+		Login *struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			TOTP     string `json:"totp"`
+			URIs     []struct {
+				URI string `json:"uri"`
+			} `json:"uris"`
+		} `json:"login"`
+	} `json:"items"`
+}
+
+// parseBitwarden reads a Bitwarden JSON export. Folders, or the first collection of organisation
+// items, become directories; Bitwarden separates nested folders with slashes already.
+func parseBitwarden(r io.Reader) ([]Record, error) {
+	var export bitwardenExport
+	err := json.NewDecoder(r).Decode(&export)
+	if err != nil {
+		return nil, err
+	}
+	if export.Encrypted {
+		return nil, errEncryptedExport
+	}
+	if export.Items == nil {
+		return nil, errors.New("not a Bitwarden JSON export, it has no items")
+	}
+
+	folders := map[string]string{}
+	for _, folder := range export.Folders {
+		folders[folder.ID] = folder.Name
+	}
+	for _, collection := range export.Collections {
+		folders[collection.ID] = collection.Name
+	}
+
+	var records []Record
+	for _, item := range export.Items {
+		record := Record{
+			Folder: folders[item.FolderID],
+			Name:   item.Name,
+			Notes:  item.Notes,
+		}
+		if record.Folder == "" && len(item.CollectionIDs) > 0 {
+			record.Folder = folders[item.CollectionIDs[0]]
+		}
+
+		if item.Login != nil {
+			record.Username = item.Login.Username
+			record.Password = item.Login.Password
+			record.OTP = item.Login.TOTP
+			for i, uri := range item.Login.URIs {
+				if i == 0 {
+					record.URL = uri.URI
+					continue
+				}
+				record.Fields = append(record.Fields, entry.Field{Key: "url", Value: uri.URI})
+			}
+		}
+
+		for _, field := range item.Fields {
+			record.Fields = append(record.Fields, entry.Field{Key: field.Name, Value: field.Value})
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func init() {
+	Register("bitwarden", "an unencrypted Bitwarden JSON export", parseBitwarden)
+}
@@ -0,0 +1,119 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/torbenconto/gopwd/internal/entry"
+)
+
+// csvColumns maps the parts of a Record to the header names they may have in a CSV export.
+type csvColumns struct {
+	Folder, Name, Password, Username, URL, OTP, Notes []string
+	// Tags hold comma separated tags, the first of which is the folder if Folder is empty.
+	Tags []string
+}
+
+// csvRows reads a CSV export with a header row and returns each row keyed by lower-case header.
+func csvRows(r io.Reader) ([]map[string]string, []string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("missing header row")
+	}
+
+	header := make([]string, len(rows[0]))
+	for i, name := range rows[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	}
+
+	var result []map[string]string
+	for _, row := range rows[1:] {
+		values := map[string]string{}
+		for i, value := range row {
+			if i < len(header) {
+				values[header[i]] = value
+			}
+		}
+		result = append(result, values)
+	}
+
+	return result, header, nil
+}
+
+// csvRecords reads a CSV export whose columns are described by columns. Columns not mentioned
+// there become fields, except those listed in ignore.
+func csvRecords(r io.Reader, columns csvColumns, ignore ...string) ([]Record, error) {
+	rows, header, err := csvRows(r)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(columns.Password, func(name string) bool { return slices.Contains(header, name) }) {
+		return nil, fmt.Errorf("missing %s column", strings.Join(columns.Password, " or "))
+	}
+
+	known := map[string]bool{}
+	for _, names := range [][]string{columns.Folder, columns.Name, columns.Password, columns.Username, columns.URL, columns.OTP, columns.Notes, columns.Tags, ignore} {
+		for _, name := range names {
+			known[name] = true
+		}
+	}
+
+	var records []Record
+	for _, row := range rows {
+		get := func(names []string) string {
+			for _, name := range names {
+				if value := row[name]; value != "" {
+					return value
+				}
+			}
+			return ""
+		}
+
+		record := Record{
+			Folder:   get(columns.Folder),
+			Name:     get(columns.Name),
+			Password: get(columns.Password),
+			Username: get(columns.Username),
+			URL:      get(columns.URL),
+			OTP:      get(columns.OTP),
+			Notes:    get(columns.Notes),
+		}
+		if tags := get(columns.Tags); record.Folder == "" && tags != "" {
+			record.Folder = strings.TrimSpace(strings.Split(tags, ",")[0])
+		}
+		for _, name := range header {
+			if !known[name] && row[name] != "" {
+				record.Fields = append(record.Fields, entry.Field{Key: name, Value: row[name]})
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// parseBrowserCSV reads the password exports of Chrome, Edge, Firefox and Safari.
+func parseBrowserCSV(r io.Reader) ([]Record, error) {
+	return csvRecords(r, csvColumns{
+		Name:     []string{"name", "title"},
+		Password: []string{"password"},
+		Username: []string{"username"},
+		URL:      []string{"url", "origin"},
+		OTP:      []string{"otpauth"},
+		Notes:    []string{"note", "notes"},
+	}, "httprealm", "formactionorigin", "guid", "timecreated", "timelastused", "timepasswordchanged")
+}
+
+func init() {
+	Register("csv", "a Chrome, Edge, Firefox or Safari password CSV export", parseBrowserCSV)
+}
@@ -0,0 +1,214 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"sync"
+
+	gopwdio "github.com/torbenconto/gopwd/internal/io"
+	"github.com/torbenconto/gopwd/internal/util"
+)
+
+// Strategy decides what happens when an imported entry already exists in the vault.
+type Strategy string
+
+const (
+	Skip      Strategy = "skip"
+	Overwrite Strategy = "overwrite"
+	Rename    Strategy = "rename"
+)
+
+// Strategies lists the valid conflict strategies.
+var Strategies = []Strategy{Skip, Overwrite, Rename}
+
+// renameSuffix is appended to the name of a conflicting entry under the Rename strategy.
+const renameSuffix = "-imported"
+
+// Action is what an import does with one entry.
+type Action string
+
+const (
+	// Copy stores the ciphertext as it is because the recipients already match.
+	Copy Action = "copy"
+	// Reencrypt decrypts the entry and encrypts it for the recipients of the vault.
+	Reencrypt Action = "re-encrypt"
+	// Encrypt encrypts a plaintext record for the recipients of the vault.
+	Encrypt Action = "encrypt"
+	// Ignore leaves a conflicting entry alone.
+	Ignore Action = "skip"
+)
+
+// Item is an entry being imported and the plan for it.
+type Item struct {
+	// Service is the name of the entry in the source.
+	Service string
+	// Target is the name the entry gets in the vault.
+	Target   string
+	Action   Action
+	Conflict bool
+}
+
+// Parser reads an export file of another password manager.
+type Parser func(r io.Reader) ([]Record, error)
+
+type registration struct {
+	description string
+	parser      Parser
+}
+
+var (
+	parsersMu sync.RWMutex
+	parsers   = map[string]registration{}
+)
+
+// Register makes a parser available under name, described by the kind of export it reads. It
+// panics if name is already taken.
+func Register(name, description string, parser Parser) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+
+	if _, ok := parsers[name]; ok {
+		panic("importer: parser registered twice: " + name)
+	}
+	parsers[name] = registration{description: description, parser: parser}
+}
+
+// Describe returns the description the parser registered under name was registered with.
+func Describe(name string) string {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+
+	return parsers[name].description
+}
+
+// Formats returns the names of all registered parsers.
+func Formats() []string {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+
+	names := make([]string, 0, len(parsers))
+	for name := range parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse reads r with the parser registered under format.
+func Parse(format string, r io.Reader) ([]Record, error) {
+	parsersMu.RLock()
+	reg, ok := parsers[format]
+	parsersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown import format %q (available: %v)", format, Formats())
+	}
+
+	records, err := reg.parser(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s export: %v", format, err)
+	}
+
+	return records, nil
+}
+
+// RecordItem is a parsed record and the plan for importing it.
+type RecordItem struct {
+	Item
+	Record Record
+}
+
+// PlanRecords decides where each record goes in the vault, below prefix if it is not empty.
+// Records that would share a name are numbered.
+func PlanRecords(vaultPath string, records []Record, prefix string, strategy Strategy) ([]RecordItem, error) {
+	if !slices.Contains(Strategies, strategy) {
+		return nil, fmt.Errorf("unknown conflict strategy %q (available: %v)", strategy, Strategies)
+	}
+
+	items := make([]RecordItem, len(records))
+	seen := map[string]bool{}
+	taken := map[string]bool{}
+	for i, record := range records {
+		service := record.Service(prefix)
+		name := service
+		for n := 2; seen[name]; n++ {
+			name = fmt.Sprintf("%s-%d", service, n)
+		}
+		seen[name] = true
+
+		items[i] = RecordItem{
+			Item:   Item{Service: name, Action: Encrypt},
+			Record: record,
+		}
+		resolve(vaultPath, &items[i].Item, strategy, taken)
+	}
+
+	return items, nil
+}
+
+// ImportRecords encrypts the planned records for their place in the vault and writes them using
+// at most workers goroutines. progress is passed on to util.ForEachService.
+func ImportRecords(vaultPath string, items []RecordItem, backends *util.BackendCache, workers int, progress func(done, total int, service string, err error)) map[string]error {
+	byTarget := map[string]RecordItem{}
+	var targets []string
+	for _, item := range items {
+		if item.Action == Ignore {
+			continue
+		}
+		byTarget[item.Target] = item
+		targets = append(targets, item.Target)
+	}
+
+	// Writes create directories, so they happen one at a time
+	var writeMu sync.Mutex
+
+	return util.ForEachService(targets, workers, func(target string) error {
+		backend, err := backends.Get(target)
+		if err != nil {
+			return err
+		}
+
+		encrypted, err := backend.Encrypt(byTarget[target].Record.Content())
+		if err != nil {
+			return fmt.Errorf("failed to encrypt password: %v", err)
+		}
+
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return util.CreateStructureAndClean(target, vaultPath, util.ServicePath(vaultPath, target), encrypted)
+	}, progress)
+}
+
+// resolve sets the target of item, applying strategy if its name is already used in the vault.
+// Names used by another item of the import, as recorded in taken, are always renamed, so no
+// two items are written to the same place.
+func resolve(vaultPath string, item *Item, strategy Strategy, taken map[string]bool) {
+	item.Target = item.Service
+
+	if gopwdio.Exists(util.ServicePath(vaultPath, item.Target)) {
+		item.Conflict = true
+		switch strategy {
+		case Skip:
+			item.Action = Ignore
+			return
+		case Rename:
+			item.Target = freeName(vaultPath, item.Service, taken)
+		}
+	}
+
+	if taken[item.Target] {
+		item.Target = freeName(vaultPath, item.Service, taken)
+	}
+
+	taken[item.Target] = true
+}
+
+// freeName returns the first name based on service that is neither in the vault nor taken.
+func freeName(vaultPath, service string, taken map[string]bool) string {
+	name := service + renameSuffix
+	for i := 2; taken[name] || gopwdio.Exists(util.ServicePath(vaultPath, name)); i++ {
+		name = fmt.Sprintf("%s%s-%d", service, renameSuffix, i)
+	}
+	return name
+}
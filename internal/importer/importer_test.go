@@ -0,0 +1,149 @@
+package importer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	type want struct {
+		service string
+		content string
+	}
+
+	tests := []struct {
+		format string
+		file   string
+		want   []want
+	}{
+		{"csv", "browser.csv", []want{
+			{"github.com", "gh-secret\nusername: alice\nurl: https://github.com/login"},
+			{"example.org", "pa,ss\nusername: bob\nurl: https://example.org/\ntwo line"},
+		}},
+		{"lastpass", "lastpass.csv", []want{
+			{"Personal/Email/Mail", "mail-pw\nusername: alice@example.com\nurl: https://mail.example.com\notpauth://totp/Mail?secret=JBSWY3DPEHPK3PXP\nbackup codes:\n1234 5678"},
+			{"Home/Wifi", "\nthe wifi password is in the drawer"},
+		}},
+		{"1password", "1password.csv", []want{
+			{"finance/Bank", "bank-pw\nusername: alice\nurl: https://bank.example.com\npin: 1234\ncall them first"},
+			{"Router", "router-pw\nusername: admin\notpauth://totp/Router?secret=JBSWY3DPEHPK3PXP"},
+		}},
+		{"bitwarden", "bitwarden.json", []want{
+			{"Social/Personal/Mastodon", "toot-pw\nusername: alice\nurl: https://mastodon.social\nurl: https://m.example\nrecovery email: alice@example.org\notpauth://totp/Mastodon?secret=JBSWY3DPEHPK3PXP\nmain account"},
+			{"Shared/Team wiki", "wiki-pw\nusername: team"},
+			{"Alarm code", "\n4711"},
+		}},
+		{"keepass", "keepass.xml", []want{
+			{"Server", "srv-pw\nusername: root\nurl: ssh://server.example.com\nPort: 2222\nrotate monthly"},
+			{"Work-Internal/VPN", "vpn-pw\nusername: alice"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			file, err := os.Open(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			records, err := Parse(tt.format, file)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if len(records) != len(tt.want) {
+				t.Fatalf("Parse returned %d records, want %d", len(records), len(tt.want))
+			}
+
+			for i, record := range records {
+				if got := record.Service(""); got != tt.want[i].service {
+					t.Errorf("record %d: Service() = %q, want %q", i, got, tt.want[i].service)
+				}
+				if got := string(record.Content()); got != tt.want[i].content {
+					t.Errorf("record %d: Content() = %q, want %q", i, got, tt.want[i].content)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		err    string
+	}{
+		{"empty csv", "csv", "", "missing header row"},
+		{"csv without password column", "csv", "name,url,username\ngithub,https://github.com,alice\n", "missing password column"},
+		{"csv with bad quoting", "csv", "name,password\n\"github,secret\n", "extraneous or missing"},
+		{"lastpass without password column", "lastpass", "url,username,name\nhttps://a.example,alice,a\n", "missing password column"},
+		{"1password without password column", "1password", "Title,Username\nBank,alice\n", "missing password column"},
+		{"bad json", "bitwarden", `{"items": [`, "unexpected EOF"},
+		{"json of another format", "bitwarden", `{"entries": []}`, "not a Bitwarden JSON export"},
+		{"bad xml", "keepass", "<KeePassFile><Root><Group>", "unexpected EOF"},
+		{"xml of another format", "keepass", "<database><entry/></database>", "expected element type <KeePassFile>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.format, strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Parse error = %v, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseEncryptedBitwarden(t *testing.T) {
+	_, err := parseBitwarden(strings.NewReader(`{"encrypted": true, "items": []}`))
+	if !errors.Is(err, errEncryptedExport) {
+		t.Errorf("parseBitwarden error = %v, want %v", err, errEncryptedExport)
+	}
+}
+
+func TestPlanRecordsUniqueTargets(t *testing.T) {
+	vaultPath := t.TempDir()
+	err := os.WriteFile(filepath.Join(vaultPath, "foo.gpg"), nil, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, names := range [][]string{{"foo", "foo-imported"}, {"foo-imported", "foo"}} {
+		records := []Record{{Name: names[0], Password: "a"}, {Name: names[1], Password: "b"}}
+
+		for _, strategy := range Strategies {
+			items, err := PlanRecords(vaultPath, records, "", strategy)
+			if err != nil {
+				t.Fatalf("PlanRecords error: %v", err)
+			}
+
+			targets := map[string]bool{}
+			for _, item := range items {
+				if item.Action == Ignore {
+					continue
+				}
+				if targets[item.Target] {
+					t.Errorf("%v with %s: two records planned for %s", names, strategy, item.Target)
+				}
+				targets[item.Target] = true
+			}
+		}
+	}
+}
+
+func TestContentMultilinePassword(t *testing.T) {
+	record := Record{Password: "first\r\nusername: mallory", Username: "alice"}
+	want := "first username: mallory\nusername: alice"
+	if got := string(record.Content()); got != want {
+		t.Errorf("Content() = %q, want %q", got, want)
+	}
+
+	record = Record{Password: "  spaced  out "}
+	if got := string(record.Content()); got != record.Password {
+		t.Errorf("Content() = %q, want the single-line password unchanged", got)
+	}
+}
@@ -0,0 +1,90 @@
+package importer
+
+import (
+	"encoding/xml"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/torbenconto/gopwd/internal/entry"
+)
+
+// keePassFile is the XML export of a KeePass 2 or KeePassXC database.
+type keePassFile struct {
+	XMLName xml.Name `xml:"KeePassFile"`
+	Meta    struct {
+		RecycleBinUUID string `xml:"RecycleBinUUID"`
+	} `xml:"Meta"`
+	Root struct {
+		Groups []keePassGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+type keePassGroup struct {
+	UUID    string         `xml:"UUID"`
+	Name    string         `xml:"Name"`
+	Entries []keePassEntry `xml:"Entry"`
+	Groups  []keePassGroup `xml:"Group"`
+}
+
+type keePassEntry struct {
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"String"`
+}
+
+// parseKeePass reads a KeePass XML export. Groups below the database's root group become
+// directories and the recycle bin is left out.
+func parseKeePass(r io.Reader) ([]Record, error) {
+	var file keePassFile
+	err := xml.NewDecoder(r).Decode(&file)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	var walk func(group keePassGroup, folder string)
+	walk = func(group keePassGroup, folder string) {
+		if group.UUID != "" && group.UUID == file.Meta.RecycleBinUUID {
+			return
+		}
+
+		for _, e := range group.Entries {
+			record := Record{Folder: folder}
+			for _, s := range e.Strings {
+				switch strings.ToLower(s.Key) {
+				case "title":
+					record.Name = s.Value
+				case "username":
+					record.Username = s.Value
+				case "password":
+					record.Password = s.Value
+				case "url":
+					record.URL = s.Value
+				case "notes":
+					record.Notes = s.Value
+				case "otp":
+					record.OTP = s.Value
+				default:
+					record.Fields = append(record.Fields, entry.Field{Key: s.Key, Value: s.Value})
+				}
+			}
+			records = append(records, record)
+		}
+
+		for _, child := range group.Groups {
+			walk(child, path.Join(folder, strings.ReplaceAll(child.Name, "/", "-")))
+		}
+	}
+
+	for _, root := range file.Root.Groups {
+		walk(root, "")
+	}
+
+	return records, nil
+}
+
+func init() {
+	Register("keepass", "a KeePass or KeePassXC XML export", parseKeePass)
+}
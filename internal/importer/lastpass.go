@@ -0,0 +1,38 @@
+package importer
+
+import (
+	"io"
+	"strings"
+)
+
+// lastPassNoteURL marks secure notes in LastPass exports.
+const lastPassNoteURL = "http://sn"
+
+// parseLastPass reads a LastPass CSV export. Groups are separated by backslashes.
+func parseLastPass(r io.Reader) ([]Record, error) {
+	records, err := csvRecords(r, csvColumns{
+		Folder:   []string{"grouping"},
+		Name:     []string{"name"},
+		Password: []string{"password"},
+		Username: []string{"username"},
+		URL:      []string{"url"},
+		OTP:      []string{"totp"},
+		Notes:    []string{"extra"},
+	}, "fav")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range records {
+		records[i].Folder = strings.ReplaceAll(records[i].Folder, "\\", "/")
+		if records[i].URL == lastPassNoteURL {
+			records[i].URL = ""
+		}
+	}
+
+	return records, nil
+}
+
+func init() {
+	Register("lastpass", "a LastPass CSV export", parseLastPass)
+}
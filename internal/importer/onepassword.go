@@ -0,0 +1,22 @@
+package importer
+
+import "io"
+
+// parseOnePassword reads a 1Password CSV export. The vault column of exports that have one, or
+// else the first tag, becomes the folder.
+func parseOnePassword(r io.Reader) ([]Record, error) {
+	return csvRecords(r, csvColumns{
+		Folder:   []string{"vault"},
+		Name:     []string{"title"},
+		Password: []string{"password"},
+		Username: []string{"username"},
+		URL:      []string{"url", "website"},
+		OTP:      []string{"otpauth", "one-time password"},
+		Notes:    []string{"notes", "notesplain"},
+		Tags:     []string{"tags"},
+	}, "favorite", "archived", "type")
+}
+
+func init() {
+	Register("1password", "a 1Password CSV export", parseOnePassword)
+}
@@ -0,0 +1,183 @@
+package importer
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/crypt/openpgp"
+	"github.com/torbenconto/gopwd/internal/io"
+	"github.com/torbenconto/gopwd/internal/util"
+)
+
+// PassEntry is an entry of a pass store and the plan for importing it.
+type PassEntry struct {
+	Item
+	Path       string
+	Recipients []string
+}
+
+// PlanPass lists the entries of the pass store in dir and decides how each is imported into the
+// vault. Hidden files and directories such as .git are skipped and each entry is matched with the
+// nearest .gpg-id above it. Entries whose recipients already equal those of their place in the
+// vault are copied, the rest re-encrypted.
+func PlanPass(vaultPath, dir string, strategy Strategy, backends *util.BackendCache) ([]PassEntry, error) {
+	if !slices.Contains(Strategies, strategy) {
+		return nil, fmt.Errorf("unknown conflict strategy %q (available: %v)", strategy, Strategies)
+	}
+
+	format, err := util.VaultFormat(vaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dir = filepath.Clean(dir)
+	var entries []PassEntry
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".gpg") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		recipients, err := passRecipients(dir, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, PassEntry{
+			Item:       Item{Service: filepath.ToSlash(strings.TrimSuffix(rel, ".gpg"))},
+			Path:       path,
+			Recipients: recipients,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pass store: %v", err)
+	}
+
+	// Targets already handed out, so two renamed entries never collide
+	taken := map[string]bool{}
+	for i := range entries {
+		e := &entries[i]
+		resolve(vaultPath, &e.Item, strategy, taken)
+		if e.Action == Ignore {
+			continue
+		}
+
+		backend, err := backends.Get(e.Target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load encryption backend for %s: %v", e.Target, err)
+		}
+
+		e.Action = Reencrypt
+		if format.Extension == ".gpg" && sameRecipients(e.Recipients, backend.Recipients()) {
+			e.Action = Copy
+		}
+	}
+
+	return entries, nil
+}
+
+// ImportPass carries out a plan made by PlanPass using at most workers goroutines. progress is
+// passed on to util.ForEachService.
+func ImportPass(vaultPath string, entries []PassEntry, backends *util.BackendCache, workers int, progress func(done, total int, service string, err error)) (map[string]error, error) {
+	byService := map[string]PassEntry{}
+	var services []string
+	for _, e := range entries {
+		if e.Action == Ignore {
+			continue
+		}
+		byService[e.Service] = e
+		services = append(services, e.Service)
+	}
+
+	source, err := passBackend(vaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Writes create directories, so they happen one at a time
+	var writeMu sync.Mutex
+
+	return util.ForEachService(services, workers, func(service string) error {
+		e := byService[service]
+
+		data, err := io.ReadFile(e.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %v", err)
+		}
+
+		if e.Action == Reencrypt {
+			plaintext, err := source.Decrypt(data)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt password: %v", err)
+			}
+
+			backend, err := backends.Get(e.Target)
+			if err != nil {
+				return err
+			}
+
+			data, err = backend.Encrypt(plaintext)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt password: %v", err)
+			}
+		}
+
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return util.CreateStructureAndClean(e.Target, vaultPath, util.ServicePath(vaultPath, e.Target), data)
+	}, progress), nil
+}
+
+// passRecipients returns the recipients in the nearest .gpg-id at or above dir within root.
+func passRecipients(root, dir string) ([]string, error) {
+	for {
+		file := filepath.Join(dir, ".gpg-id")
+		if io.Exists(file) {
+			return util.ReadGPGID(file)
+		}
+		if dir == root {
+			return nil, fmt.Errorf("no .gpg-id found in %s", root)
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// passBackend returns a backend that decrypts pass entries, preferring the vault's own backend
+// when it reads OpenPGP.
+func passBackend(vaultPath string) (crypt.Backend, error) {
+	name := crypt.DefaultBackend
+	if util.BackendName(vaultPath) == openpgp.Name {
+		name = openpgp.Name
+	}
+
+	return crypt.New(name, crypt.Config{Keyring: viper.GetString("keyring")})
+}
+
+func sameRecipients(a, b []string) bool {
+	a = slices.Clone(a)
+	b = slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
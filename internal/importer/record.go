@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/torbenconto/gopwd/internal/entry"
+	"github.com/torbenconto/gopwd/internal/otp"
+)
+
+// Record is an entry read from another password manager's export.
+type Record struct {
+	// Folder is the slash separated folder or collection of the entry and becomes its directory.
+	Folder   string
+	Name     string
+	Password string
+	Username string
+	URL      string
+	// OTP is an otpauth:// URI or a base32 TOTP secret.
+	OTP    string
+	Notes  string
+	Fields []entry.Field
+}
+
+// Service returns the vault name of the record below prefix. Unusable path segments such as ".."
+// are dropped and names fall back to the host of the URL.
+func (r Record) Service(prefix string) string {
+	var parts []string
+	for _, folder := range []string{prefix, r.Folder} {
+		for _, part := range strings.Split(folder, "/") {
+			if part = cleanName(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+	}
+
+	name := cleanName(strings.ReplaceAll(r.Name, "/", "-"))
+	if name == "" {
+		if u, err := url.Parse(r.URL); err == nil {
+			name = cleanName(u.Hostname())
+		}
+	}
+	if name == "" {
+		name = "untitled"
+	}
+
+	return path.Join(append(parts, name)...)
+}
+
+// Content returns the record in the pass entry format: the password on the first line, then
+// username, url and any other fields, the otpauth:// URI and finally the notes. Passwords that
+// span several lines are joined, so they can't be mistaken for fields.
+func (r Record) Content() []byte {
+	password := r.Password
+	if strings.ContainsAny(password, "\r\n") {
+		password = oneLine(password)
+	}
+	lines := []string{password}
+
+	if r.Username != "" {
+		lines = append(lines, "username: "+oneLine(r.Username))
+	}
+	if r.URL != "" {
+		lines = append(lines, "url: "+oneLine(r.URL))
+	}
+	for _, field := range r.Fields {
+		if field.Value != "" {
+			lines = append(lines, oneLine(field.Key)+": "+oneLine(field.Value))
+		}
+	}
+
+	if r.OTP != "" {
+		if strings.HasPrefix(r.OTP, otp.Scheme) {
+			lines = append(lines, r.OTP)
+		} else if key, err := otp.NewTOTP(r.Name, r.OTP); err == nil {
+			lines = append(lines, key.URI())
+		} else {
+			lines = append(lines, "otp: "+oneLine(r.OTP))
+		}
+	}
+
+	if notes := strings.TrimSpace(strings.ReplaceAll(r.Notes, "\r\n", "\n")); notes != "" {
+		lines = append(lines, notes)
+	}
+
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// cleanName makes s usable as a single path segment of a service.
+func cleanName(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimLeft(s, ".")
+	s = strings.ReplaceAll(s, "\\", "-")
+	return strings.TrimSpace(s)
+}
+
+// oneLine joins the lines of s so it fits on a field line.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
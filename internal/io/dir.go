@@ -6,7 +6,9 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 )
 
 func GetHomeDir() string {
@@ -53,7 +55,42 @@ func Cleanup(files []string, dirs []string) error {
 	return nil
 }
 
-// listServicesRecursive is a helper function that recursively searches for .gpg files.
+var (
+	extensionsMu sync.RWMutex
+	extensions   []string
+)
+
+// RegisterExtension adds ext to the file extensions of encrypted entries. The crypt package
+// registers the extension of every backend.
+func RegisterExtension(ext string) {
+	extensionsMu.Lock()
+	defer extensionsMu.Unlock()
+
+	if !slices.Contains(extensions, ext) {
+		extensions = append(extensions, ext)
+	}
+}
+
+// Extensions returns the file extensions of encrypted entries, one per encryption backend.
+func Extensions() []string {
+	extensionsMu.RLock()
+	defer extensionsMu.RUnlock()
+
+	return slices.Clone(extensions)
+}
+
+// TrimExtension strips the entry extension from name and reports whether name is an entry.
+func TrimExtension(name string) (string, bool) {
+	for _, ext := range Extensions() {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext), true
+		}
+	}
+	return name, false
+}
+
+// listServicesRecursive is a helper function that recursively searches for entry files.
+// Hidden files and directories such as .gpg-id, .git and .trash are skipped.
 func listServicesRecursive(vaultPath string, services *[]string, relativePath string) error {
 	entries, err := os.ReadDir(vaultPath)
 	if err != nil {
@@ -61,14 +98,18 @@ func listServicesRecursive(vaultPath string, services *[]string, relativePath st
 	}
 
 	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
 		fullPath := filepath.Join(vaultPath, entry.Name())
 		if entry.IsDir() {
 			err := listServicesRecursive(fullPath, services, filepath.Join(relativePath, entry.Name()))
 			if err != nil {
 				return err
 			}
-		} else if strings.HasSuffix(entry.Name(), ".gpg") {
-			serviceRelativePath := filepath.Join(relativePath, strings.TrimSuffix(entry.Name(), ".gpg"))
+		} else if name, ok := TrimExtension(entry.Name()); ok {
+			serviceRelativePath := filepath.Join(relativePath, name)
 			*services = append(*services, serviceRelativePath)
 		}
 	}
@@ -76,7 +117,7 @@ func listServicesRecursive(vaultPath string, services *[]string, relativePath st
 	return nil
 }
 
-// ListServices returns a slice of available service names by recursively reading .gpg files in the vault.
+// ListServices returns a slice of available service names by recursively reading entry files in the vault.
 func ListServices(vaultPath string) ([]string, error) {
 	var services []string
 	err := listServicesRecursive(vaultPath, &services, "")
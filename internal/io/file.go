@@ -3,6 +3,7 @@ package io
 import (
 	"io"
 	"os"
+	"path/filepath"
 )
 
 func CreateFile(file string) (*os.File, error) {
@@ -57,3 +58,30 @@ func CreateTempFile(content []byte) (*os.File, error) {
 
 	return tmpfile, nil
 }
+
+// WriteFileAtomic writes data to a temporary file next to file and renames it into place,
+// so a crash or error never leaves file half-written.
+func WriteFileAtomic(file string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
@@ -6,3 +6,7 @@ func Exists(path string) bool {
 	_, err := os.Stat(path)
 	return !os.IsNotExist(err)
 }
+
+func Stat(path string) (os.FileInfo, error) {
+	return os.Stat(path)
+}
@@ -0,0 +1,204 @@
+package otp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TOTP = "totp"
+	HOTP = "hotp"
+
+	// Scheme starts the otpauth:// line stored in an entry.
+	Scheme = "otpauth://"
+
+	defaultDigits = 6
+	defaultPeriod = 30
+)
+
+// Key is a one-time password seed parsed from an otpauth:// URI.
+type Key struct {
+	Type      string
+	Label     string
+	Secret    []byte
+	Algorithm string
+	Digits    int
+	Period    int
+	Counter   uint64
+
+	uri *url.URL
+}
+
+// ParseURI parses an otpauth://totp/... or otpauth://hotp/... URI.
+func ParseURI(uri string) (*Key, error) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		return nil, fmt.Errorf("invalid otpauth URI: %v", err)
+	}
+	if u.Scheme != "otpauth" {
+		return nil, fmt.Errorf("invalid otpauth URI: scheme is %q", u.Scheme)
+	}
+
+	key := &Key{
+		Type:      strings.ToLower(u.Host),
+		Label:     strings.TrimPrefix(u.Path, "/"),
+		Algorithm: "SHA1",
+		Digits:    defaultDigits,
+		Period:    defaultPeriod,
+		uri:       u,
+	}
+	if key.Type != TOTP && key.Type != HOTP {
+		return nil, fmt.Errorf("unsupported OTP type %q", u.Host)
+	}
+
+	query := u.Query()
+
+	key.Secret, err = DecodeSecret(query.Get("secret"))
+	if err != nil {
+		return nil, err
+	}
+
+	if algorithm := query.Get("algorithm"); algorithm != "" {
+		key.Algorithm = strings.ToUpper(algorithm)
+		if _, err := key.hash(); err != nil {
+			return nil, err
+		}
+	}
+	if digits := query.Get("digits"); digits != "" {
+		key.Digits, err = strconv.Atoi(digits)
+		if err != nil || key.Digits < 6 || key.Digits > 10 {
+			return nil, fmt.Errorf("invalid digits %q", digits)
+		}
+	}
+	if period := query.Get("period"); period != "" {
+		key.Period, err = strconv.Atoi(period)
+		if err != nil || key.Period < 1 {
+			return nil, fmt.Errorf("invalid period %q", period)
+		}
+	}
+	if key.Type == HOTP {
+		key.Counter, err = strconv.ParseUint(query.Get("counter"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid counter %q", query.Get("counter"))
+		}
+	}
+
+	return key, nil
+}
+
+// NewTOTP builds a TOTP key with the default settings from a base32 secret.
+func NewTOTP(label, secret string) (*Key, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	if _, err := DecodeSecret(secret); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+
+	return ParseURI((&url.URL{
+		Scheme:   "otpauth",
+		Host:     TOTP,
+		Path:     "/" + label,
+		RawQuery: query.Encode(),
+	}).String())
+}
+
+// DecodeSecret decodes a base32 secret, with or without padding.
+func DecodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	if secret == "" {
+		return nil, fmt.Errorf("missing OTP secret")
+	}
+
+	decoded, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base32 OTP secret: %v", err)
+	}
+
+	return decoded, nil
+}
+
+// URI returns the key as an otpauth:// URI, including the current HOTP counter.
+func (k *Key) URI() string {
+	u := *k.uri
+	if k.Type == HOTP {
+		query := u.Query()
+		query.Set("counter", strconv.FormatUint(k.Counter, 10))
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// Code returns the TOTP code for t, or the HOTP code for the current counter.
+func (k *Key) Code(t time.Time) (string, error) {
+	counter := k.Counter
+	if k.Type == TOTP {
+		counter = uint64(t.Unix()) / uint64(k.Period)
+	}
+
+	return k.code(counter)
+}
+
+// Remaining returns how long the TOTP code for t stays valid.
+func (k *Key) Remaining(t time.Time) time.Duration {
+	period := int64(k.Period)
+	return time.Duration(period-t.Unix()%period) * time.Second
+}
+
+// code implements the HOTP algorithm from RFC 4226.
+func (k *Key) code(counter uint64) (string, error) {
+	h, err := k.hash()
+	if err != nil {
+		return "", err
+	}
+
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, counter)
+
+	mac := hmac.New(h, k.Secret)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint64(1)
+	for i := 0; i < k.Digits; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", k.Digits, uint64(value)%modulo), nil
+}
+
+func (k *Key) hash() (func() hash.Hash, error) {
+	switch k.Algorithm {
+	case "SHA1":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA512":
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("unsupported OTP algorithm %q", k.Algorithm)
+}
+
+// FindLine returns the index of the first otpauth:// line in lines.
+func FindLine(lines []string) (int, bool) {
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), Scheme) {
+			return i, true
+		}
+	}
+	return 0, false
+}
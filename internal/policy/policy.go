@@ -0,0 +1,100 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/torbenconto/gopwd/internal/pwgen"
+)
+
+// Key is the .gopwd.yaml key holding the policies. Each policy sets generator options, named as
+// in pwgen.PasswordGeneratorConfig, and lists the service paths it applies to:
+//
+//	policies:
+//	  bank:
+//	    length: 20
+//	    symbols: false
+//	    paths: ["bank/*"]
+const Key = "policies"
+
+// Names returns the names of the policies in the config.
+func Names() []string {
+	names := make([]string, 0)
+	for name := range viper.GetStringMap(Key) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Find returns the policy with a path pattern matching service. When several match, the
+// longest, most specific pattern wins.
+func Find(service string) (string, bool) {
+	var best, bestPattern string
+	for _, name := range Names() {
+		for _, pattern := range viper.GetStringSlice(Key + "." + name + ".paths") {
+			if Match(pattern, service) && len(pattern) > len(bestPattern) {
+				best, bestPattern = name, pattern
+			}
+		}
+	}
+	return best, best != ""
+}
+
+// Resolve returns the policy for service: override if it is set, otherwise the one found by
+// Find. An empty name means no policy applies.
+func Resolve(service, override string) (string, error) {
+	if override == "" {
+		name, _ := Find(service)
+		return name, nil
+	}
+
+	name := strings.ToLower(override)
+	if !viper.IsSet(Key + "." + name) {
+		return "", fmt.Errorf("unknown policy %q (available: %v)", override, Names())
+	}
+	return name, nil
+}
+
+// Apply overwrites the options of config that the policy called name sets.
+func Apply(name string, config *pwgen.PasswordGeneratorConfig) error {
+	err := viper.UnmarshalKey(Key+"."+name, config)
+	if err != nil {
+		return fmt.Errorf("invalid policy %s: %v", name, err)
+	}
+	return nil
+}
+
+// Match reports whether service matches the glob pattern. Patterns follow path.Match, with
+// "**" matching any number of directories, so "work/**" matches everything below work.
+func Match(pattern, service string) bool {
+	return matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(strings.Trim(service, "/"), "/"))
+}
+
+func matchSegments(pattern, service []string) bool {
+	if len(pattern) == 0 {
+		return len(service) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(service); i++ {
+			if matchSegments(pattern[1:], service[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(service) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], service[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], service[1:])
+}
@@ -1,12 +1,8 @@
 package pwgen
 
 import (
-	"crypto/rand"
 	"fmt"
-	"math/big"
-	"strings"
-
-	internalRand "github.com/torbenconto/gopwd/internal/crypt/rand"
+	"math"
 )
 
 var (
@@ -20,13 +16,52 @@ type PasswordGenerator struct {
 	config PasswordGeneratorConfig
 }
 
+// PasswordGeneratorConfig holds the generator options. The json names are used by the API and
+// the mapstructure names by the policies in .gopwd.yaml.
 type PasswordGeneratorConfig struct {
-	Length    int
-	Humanized bool
-	Symbols   bool
-	Numbers   bool
-	Lowercase bool
-	Uppercase bool
+	Length    int  `json:"length" mapstructure:"length"`
+	Humanized bool `json:"humanized" mapstructure:"memorable"`
+	Symbols   bool `json:"symbols" mapstructure:"symbols"`
+	Numbers   bool `json:"numbers" mapstructure:"numbers"`
+	Lowercase bool `json:"lowercase" mapstructure:"lowercase"`
+	Uppercase bool `json:"uppercase" mapstructure:"uppercase"`
+
+	// Words makes a passphrase of this many words instead of a random password. Humanized
+	// passphrases without a word count have DefaultWords words.
+	Words int `json:"words" mapstructure:"words"`
+	// Separator joins the words of a passphrase, DefaultSeparator if nil.
+	Separator *string `json:"separator" mapstructure:"separator"`
+	// Capitalization is the capitalisation policy of a passphrase, CapitalizeNone if empty.
+	Capitalization string `json:"capitalize" mapstructure:"capitalize"`
+	// Wordlist is WordlistBIP39 (the default), WordlistEFF or the path of a wordlist file.
+	Wordlist string `json:"wordlist" mapstructure:"wordlist"`
+
+	// MinSymbols, MinNumbers, MinLowercase and MinUppercase are the least number of characters
+	// of each class in a random password.
+	MinSymbols   int `json:"min_symbols" mapstructure:"minSymbols"`
+	MinNumbers   int `json:"min_numbers" mapstructure:"minNumbers"`
+	MinLowercase int `json:"min_lowercase" mapstructure:"minLowercase"`
+	MinUppercase int `json:"min_uppercase" mapstructure:"minUppercase"`
+	// Exclude lists characters that are never used, such as the look-alikes "0O1lI".
+	Exclude string `json:"exclude" mapstructure:"exclude"`
+	// AllowedSymbols replaces Syms as the symbols to choose from, for sites that only accept some.
+	AllowedSymbols string `json:"allowed_symbols" mapstructure:"symbolSet"`
+
+	// Pattern generates a password in a fixed shape, such as "Cvccvc99!". See parsePattern for
+	// the syntax. Length and the character class options other than Exclude and AllowedSymbols
+	// are ignored.
+	Pattern string `json:"pattern" mapstructure:"pattern"`
+}
+
+// DefaultConfig returns the options generate uses when none are given.
+func DefaultConfig() PasswordGeneratorConfig {
+	return PasswordGeneratorConfig{
+		Length:    16,
+		Symbols:   true,
+		Numbers:   true,
+		Lowercase: true,
+		Uppercase: true,
+	}
 }
 
 func NewPasswordGenerator(config PasswordGeneratorConfig) *PasswordGenerator {
@@ -36,96 +71,171 @@ func NewPasswordGenerator(config PasswordGeneratorConfig) *PasswordGenerator {
 }
 
 func (pg *PasswordGenerator) Generate() (string, error) {
-	if pg.config.Humanized {
-		return pg.generateHumanized()
+	if pg.config.Pattern != "" {
+		return pg.generatePattern()
+	}
+	if pg.passphrase() {
+		return pg.generatePassphrase()
 	} else {
 		return pg.generateNonHumanized()
 	}
 }
 
-func (pg *PasswordGenerator) generateHumanized() (string, error) {
-	var password strings.Builder
+// Entropy returns the entropy in bits of the passwords the generator makes. Minimum counts make
+// random passwords slightly more predictable than this.
+func (pg *PasswordGenerator) Entropy() (float64, error) {
+	if pg.config.Pattern != "" {
+		return pg.patternEntropy()
+	}
+	if pg.passphrase() {
+		return pg.passphraseEntropy()
+	}
 
-	for password.Len() < pg.config.Length {
-		// Select a random word
-		wordIndex, err := rand.Int(rand.Reader, big.NewInt(int64(len(wordlist))))
-		if err != nil {
-			return "", fmt.Errorf("failed to generate random word index: %w", err)
-		}
-		word := wordlist[wordIndex.Int64()]
+	classes, err := pg.classes()
+	if err != nil {
+		return 0, err
+	}
 
-		// Optionally capitalize the word
-		if pg.config.Uppercase && internalRand.RandomInt(2) == 1 { // Simulate a 50% chance
-			word = strings.Title(word)
-		}
+	size := 0
+	for _, class := range classes {
+		size += len(class.characters)
+	}
+	return float64(pg.config.Length) * math.Log2(float64(size)), nil
+}
 
-		// Append the word to the password
-		if password.Len()+len(word) <= pg.config.Length {
-			password.WriteString(word)
-		} else {
-			// If adding the whole word would exceed the length, stop adding words
-			break
-		}
+// passphrase reports whether the generator makes passphrases of whole words.
+func (pg *PasswordGenerator) passphrase() bool {
+	return pg.config.Humanized || pg.config.Words > 0
+}
 
-		// Optionally insert a digit
-		if pg.config.Numbers && password.Len() < pg.config.Length {
-			digitIndex, err := rand.Int(rand.Reader, big.NewInt(int64(len(Digits))))
-			if err != nil {
-				return "", fmt.Errorf("failed to generate random digit index: %w", err)
+// class is a set of characters a random password is made of and how many of them it needs.
+type class struct {
+	name       string
+	characters []rune
+	min        int
+}
+
+// classes returns the character classes enabled in the config, without excluded characters.
+func (pg *PasswordGenerator) classes() ([]class, error) {
+	lowercase, uppercase := pg.config.Lowercase, pg.config.Uppercase
+	if !lowercase && !uppercase {
+		// Use both lower and upper case letters if neither is specified
+		lowercase, uppercase = true, true
+	}
+
+	candidates := []struct {
+		class
+		enabled bool
+	}{
+		{class{"symbols", pg.filter(pg.symbols()), pg.config.MinSymbols}, pg.config.Symbols},
+		{class{"numbers", pg.filter(Digits), pg.config.MinNumbers}, pg.config.Numbers},
+		{class{"lowercase", pg.filter(Lower), pg.config.MinLowercase}, lowercase},
+		{class{"uppercase", pg.filter(Upper), pg.config.MinUppercase}, uppercase},
+	}
+
+	var classes []class
+	required := 0
+	for _, candidate := range candidates {
+		if candidate.min < 0 {
+			return nil, fmt.Errorf("minimum %s must not be negative", candidate.name)
+		}
+		if !candidate.enabled {
+			if candidate.min > 0 {
+				return nil, fmt.Errorf("minimum %s given but %s are disabled", candidate.name, candidate.name)
 			}
-			password.WriteByte(Digits[digitIndex.Int64()])
+			continue
 		}
-
-		// Optionally insert a symbol
-		if pg.config.Symbols && password.Len() < pg.config.Length {
-			symbolIndex, err := rand.Int(rand.Reader, big.NewInt(int64(len(Syms))))
-			if err != nil {
-				return "", fmt.Errorf("failed to generate random symbol index: %w", err)
+		if len(candidate.characters) == 0 {
+			if candidate.min > 0 {
+				return nil, fmt.Errorf("minimum %s given but all %s are excluded", candidate.name, candidate.name)
 			}
-			password.WriteByte(Syms[symbolIndex.Int64()])
+			continue
 		}
+		required += candidate.min
+		classes = append(classes, candidate.class)
 	}
 
-	// Trim the password if it exceeds the desired length
-	if password.Len() > pg.config.Length {
-		return password.String()[:pg.config.Length], nil
+	if len(classes) == 0 {
+		return nil, fmt.Errorf("no characters available for password generation")
+	}
+	if required > pg.config.Length {
+		return nil, fmt.Errorf("minimum character counts add up to %d, more than the length of %d", required, pg.config.Length)
 	}
 
-	return password.String(), nil
+	return classes, nil
 }
 
-func (pg *PasswordGenerator) generateNonHumanized() (string, error) {
-	var characters string
-	if pg.config.Symbols {
-		characters += Syms
+// symbols returns the symbols to choose from.
+func (pg *PasswordGenerator) symbols() string {
+	if pg.config.AllowedSymbols != "" {
+		return pg.config.AllowedSymbols
 	}
-	if pg.config.Numbers {
-		characters += Digits
+	return Syms
+}
+
+// filter returns the distinct characters of set that are not excluded.
+func (pg *PasswordGenerator) filter(set string) []rune {
+	var characters []rune
+	seen := map[rune]bool{}
+	for _, r := range pg.config.Exclude {
+		seen[r] = true
 	}
-	if pg.config.Lowercase {
-		characters += Lower
+	for _, r := range set {
+		if !seen[r] {
+			seen[r] = true
+			characters = append(characters, r)
+		}
 	}
-	if pg.config.Uppercase {
-		characters += Upper
+	return characters
+}
+
+// generateNonHumanized picks the minimum number of characters of each class, fills up the rest
+// from all classes and shuffles the result, so required characters can end up anywhere. Every
+// choice is made with uniform random numbers.
+func (pg *PasswordGenerator) generateNonHumanized() (string, error) {
+	classes, err := pg.classes()
+	if err != nil {
+		return "", err
 	}
-	if !pg.config.Lowercase && !pg.config.Uppercase {
-		characters += Upper + Lower // Add both lower and upper case letters if neither is specified
+
+	var all []rune
+	var password []rune
+	for _, class := range classes {
+		all = append(all, class.characters...)
+		for i := 0; i < class.min; i++ {
+			r, err := pick(class.characters)
+			if err != nil {
+				return "", err
+			}
+			password = append(password, r)
+		}
 	}
 
-	var password strings.Builder
-	if len(characters) == 0 {
-		return "", fmt.Errorf("no characters available for password generation")
+	for len(password) < pg.config.Length {
+		r, err := pick(all)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, r)
 	}
 
-	for i := 0; i < pg.config.Length; i++ {
-		randomBytes := make([]byte, 1)
-		_, err := rand.Read(randomBytes)
+	// Fisher-Yates shuffle
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomIndex(i + 1)
 		if err != nil {
-			return "", fmt.Errorf("failed to generate random bytes: %w", err)
+			return "", err
 		}
-		randomIndex := int(randomBytes[0]) % len(characters)
-		password.WriteString(string(characters[randomIndex]))
+		password[i], password[j] = password[j], password[i]
 	}
 
-	return password.String(), nil
+	return string(password), nil
+}
+
+// pick returns a uniformly chosen character of set.
+func pick(set []rune) (rune, error) {
+	index, err := randomIndex(len(set))
+	if err != nil {
+		return 0, err
+	}
+	return set[index], nil
 }
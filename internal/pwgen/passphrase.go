@@ -0,0 +1,196 @@
+package pwgen
+
+import (
+	"bufio"
+	"crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Built-in wordlists for passphrases. Any other wordlist name is read as a file.
+const (
+	WordlistBIP39 = "bip39"
+	WordlistEFF   = "eff"
+)
+
+// Capitalisation policies for passphrases.
+const (
+	CapitalizeNone   = "none"
+	CapitalizeFirst  = "first"
+	CapitalizeAll    = "all"
+	CapitalizeRandom = "random"
+)
+
+// DefaultWords is the number of words of a memorable password when no word count is given.
+const DefaultWords = 5
+
+// DefaultSeparator joins the words of a passphrase when no separator is given.
+const DefaultSeparator = "-"
+
+// LoadWordlist returns the built-in wordlist called name, or the words of the file at name, one
+// per line. Lines in the dice roll format of diceware lists ("11111 abacus") use their last
+// column. Duplicate words are dropped so every word is equally likely.
+func LoadWordlist(name string) ([]string, error) {
+	switch name {
+	case "", WordlistBIP39:
+		return wordlist, nil
+	case WordlistEFF:
+		return effWordlist, nil
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wordlist: %v", err)
+	}
+	defer file.Close()
+
+	seen := map[string]bool{}
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		word := fields[len(fields)-1]
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read wordlist: %v", err)
+	}
+
+	if len(words) < 2 {
+		return nil, fmt.Errorf("wordlist %s has fewer than 2 distinct words", name)
+	}
+
+	return words, nil
+}
+
+// generatePassphrase joins randomly chosen whole words. Capitals, the digit and the symbol are
+// added to randomly chosen words, so their position carries entropy too.
+func (pg *PasswordGenerator) generatePassphrase() (string, error) {
+	switch pg.capitalization() {
+	case CapitalizeNone, CapitalizeFirst, CapitalizeAll, CapitalizeRandom:
+	default:
+		return "", fmt.Errorf("unknown capitalisation %q (available: none, first, all, random)", pg.capitalization())
+	}
+
+	words, err := LoadWordlist(pg.config.Wordlist)
+	if err != nil {
+		return "", err
+	}
+
+	count := pg.words()
+	chosen := make([]string, count)
+	for i := range chosen {
+		index, err := randomIndex(len(words))
+		if err != nil {
+			return "", err
+		}
+		chosen[i] = words[index]
+
+		switch pg.capitalization() {
+		case CapitalizeFirst:
+			chosen[i] = capitalize(chosen[i])
+		case CapitalizeAll:
+			chosen[i] = strings.ToUpper(chosen[i])
+		case CapitalizeRandom:
+			coin, err := randomIndex(2)
+			if err != nil {
+				return "", err
+			}
+			if coin == 1 {
+				chosen[i] = capitalize(chosen[i])
+			}
+		}
+	}
+
+	for _, extra := range pg.passphraseExtras() {
+		word, err := randomIndex(count)
+		if err != nil {
+			return "", err
+		}
+		r, err := pick(extra)
+		if err != nil {
+			return "", err
+		}
+		chosen[word] += string(r)
+	}
+
+	return strings.Join(chosen, pg.separator()), nil
+}
+
+// passphraseEntropy returns the entropy in bits of the passphrases generatePassphrase makes.
+func (pg *PasswordGenerator) passphraseEntropy() (float64, error) {
+	words, err := LoadWordlist(pg.config.Wordlist)
+	if err != nil {
+		return 0, err
+	}
+
+	count := float64(pg.words())
+	bits := count * math.Log2(float64(len(words)))
+	if pg.capitalization() == CapitalizeRandom {
+		bits += count
+	}
+	for _, extra := range pg.passphraseExtras() {
+		bits += math.Log2(float64(len(extra))) + math.Log2(count)
+	}
+
+	return bits, nil
+}
+
+// passphraseExtras returns the character sets of which one character is added to a passphrase.
+func (pg *PasswordGenerator) passphraseExtras() [][]rune {
+	var extras [][]rune
+	if digits := pg.filter(Digits); pg.config.Numbers && len(digits) > 0 {
+		extras = append(extras, digits)
+	}
+	if symbols := pg.filter(pg.symbols()); pg.config.Symbols && len(symbols) > 0 {
+		extras = append(extras, symbols)
+	}
+	return extras
+}
+
+func (pg *PasswordGenerator) words() int {
+	if pg.config.Words > 0 {
+		return pg.config.Words
+	}
+	return DefaultWords
+}
+
+func (pg *PasswordGenerator) separator() string {
+	if pg.config.Separator == nil {
+		return DefaultSeparator
+	}
+	return *pg.config.Separator
+}
+
+func (pg *PasswordGenerator) capitalization() string {
+	if pg.config.Capitalization == "" {
+		return CapitalizeNone
+	}
+	return pg.config.Capitalization
+}
+
+// capitalize upper-cases the first letter of word.
+func capitalize(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToUpper(r)) + word[size:]
+}
+
+// randomIndex returns a uniformly distributed random number in [0, n).
+func randomIndex(n int) (int, error) {
+	index, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate random number: %w", err)
+	}
+	return int(index.Int64()), nil
+}
@@ -0,0 +1,224 @@
+package pwgen
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MinPatternBits is the least entropy a pattern must have. It allows a 6 digit PIN (20 bits)
+// but not a 4 digit one.
+const MinPatternBits = 18
+
+// maxPatternRepeat limits repetition counts, so a typo can't ask for a huge password.
+const maxPatternRepeat = 1024
+
+// maxSetSize limits the characters of a [...] set, so a range can't span all of Unicode.
+const maxSetSize = 256
+
+const (
+	vowels     = "aeiou"
+	consonants = "bcdfghjklmnpqrstvwxyz"
+)
+
+// placeholders are the pattern characters that stand for a character class. The symbol
+// placeholders '!' and '*' are resolved by the generator, which knows the allowed symbols.
+var placeholders = map[rune]string{
+	'a': Lower,
+	'A': Upper,
+	'c': consonants,
+	'C': strings.ToUpper(consonants),
+	'v': vowels,
+	'V': strings.ToUpper(vowels),
+	'9': Digits,
+	'x': Lower + Digits,
+	'X': Upper + Digits,
+}
+
+// patternElement is one character of a pattern: a literal or a set to choose from.
+type patternElement struct {
+	set     string
+	literal bool
+	// symbols adds the allowed symbols to set.
+	symbols bool
+}
+
+// parsePattern parses a password pattern. Placeholders stand for a random character of a class:
+//
+//	a  lowercase letter     A  uppercase letter
+//	c  lowercase consonant  C  uppercase consonant
+//	v  lowercase vowel      V  uppercase vowel
+//	9  digit                !  symbol
+//	x  lowercase or digit   X  uppercase or digit
+//	*  any of the above
+//
+// [abc] or [a-f0-9] is a custom set, {n} repeats the preceding element n times and \ makes the
+// next character a literal. Every other character is a literal, so "Cvccvc99!" makes passwords
+// like "Rokbef47&" and "XXXX-XXXX-XXXX" license key shaped ones.
+func parsePattern(pattern string) ([]patternElement, error) {
+	runes := []rune(pattern)
+	var elements []patternElement
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\':
+			if i+1 == len(runes) {
+				return nil, fmt.Errorf("pattern ends with an escape")
+			}
+			i++
+			elements = append(elements, patternElement{set: string(runes[i]), literal: true})
+		case r == '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated set at position %d", i+1)
+			}
+			set, err := parseSet(runes[i+1 : end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid set at position %d: %v", i+1, err)
+			}
+			elements = append(elements, patternElement{set: set})
+			i = end
+		case r == '{':
+			end := i + 1
+			for end < len(runes) && runes[end] != '}' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated repetition at position %d", i+1)
+			}
+			if len(elements) == 0 {
+				return nil, fmt.Errorf("repetition at position %d has nothing to repeat", i+1)
+			}
+			count, err := strconv.Atoi(string(runes[i+1 : end]))
+			if err != nil || count < 1 || count > maxPatternRepeat {
+				return nil, fmt.Errorf("invalid repetition count %q at position %d", string(runes[i+1:end]), i+1)
+			}
+			last := elements[len(elements)-1]
+			for n := 1; n < count; n++ {
+				elements = append(elements, last)
+			}
+			i = end
+		case r == '!':
+			elements = append(elements, patternElement{symbols: true})
+		case r == '*':
+			elements = append(elements, patternElement{set: Lower + Upper + Digits, symbols: true})
+		default:
+			if set, ok := placeholders[r]; ok {
+				elements = append(elements, patternElement{set: set})
+			} else {
+				elements = append(elements, patternElement{set: string(r), literal: true})
+			}
+		}
+	}
+
+	if len(elements) == 0 {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	return elements, nil
+}
+
+// parseSet expands the contents of a [...] set, including ranges such as a-z.
+func parseSet(runes []rune) (string, error) {
+	var set []rune
+	for i := 0; i < len(runes); i++ {
+		first, last := runes[i], runes[i]
+		switch {
+		case first == '\\' && i+1 < len(runes):
+			i++
+			first, last = runes[i], runes[i]
+		case i+2 < len(runes) && runes[i+1] == '-':
+			last = runes[i+2]
+			if last < first {
+				return "", fmt.Errorf("range %c-%c is reversed", first, last)
+			}
+			i += 2
+		}
+
+		if len(set)+int(last-first)+1 > maxSetSize {
+			return "", fmt.Errorf("set has more than %d characters", maxSetSize)
+		}
+		for c := first; c <= last; c++ {
+			set = append(set, c)
+		}
+	}
+
+	if len(set) == 0 {
+		return "", fmt.Errorf("empty set")
+	}
+
+	return string(set), nil
+}
+
+// patternSets returns the characters to choose from for each element of the pattern, applying
+// the allowed symbols and excluded characters of the config. Literals are kept as they are.
+func (pg *PasswordGenerator) patternSets() ([][]rune, error) {
+	elements, err := parsePattern(pg.config.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %v", err)
+	}
+
+	sets := make([][]rune, len(elements))
+	for i, element := range elements {
+		switch {
+		case element.literal:
+			sets[i] = []rune(element.set)
+		case element.symbols:
+			sets[i] = pg.filter(element.set + pg.symbols())
+		default:
+			sets[i] = pg.filter(element.set)
+		}
+		if len(sets[i]) == 0 {
+			return nil, fmt.Errorf("invalid pattern: every character of element %d is excluded", i+1)
+		}
+	}
+
+	return sets, nil
+}
+
+func (pg *PasswordGenerator) patternEntropy() (float64, error) {
+	sets, err := pg.patternSets()
+	if err != nil {
+		return 0, err
+	}
+
+	bits := 0.0
+	for _, set := range sets {
+		bits += math.Log2(float64(len(set)))
+	}
+	return bits, nil
+}
+
+// generatePattern fills in the pattern, refusing patterns weaker than MinPatternBits.
+func (pg *PasswordGenerator) generatePattern() (string, error) {
+	bits, err := pg.patternEntropy()
+	if err != nil {
+		return "", err
+	}
+	if bits < MinPatternBits {
+		return "", fmt.Errorf("pattern %q only has %.1f bits of entropy, at least %d are needed", pg.config.Pattern, bits, MinPatternBits)
+	}
+
+	sets, err := pg.patternSets()
+	if err != nil {
+		return "", err
+	}
+
+	password := make([]rune, len(sets))
+	for i, set := range sets {
+		password[i], err = pick(set)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return string(password), nil
+}
@@ -0,0 +1,164 @@
+package pwgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []patternElement
+	}{
+		{"a", []patternElement{{set: Lower}}},
+		{"A", []patternElement{{set: Upper}}},
+		{"c", []patternElement{{set: consonants}}},
+		{"C", []patternElement{{set: strings.ToUpper(consonants)}}},
+		{"v", []patternElement{{set: vowels}}},
+		{"V", []patternElement{{set: strings.ToUpper(vowels)}}},
+		{"9", []patternElement{{set: Digits}}},
+		{"x", []patternElement{{set: Lower + Digits}}},
+		{"X", []patternElement{{set: Upper + Digits}}},
+		{"!", []patternElement{{symbols: true}}},
+		{"*", []patternElement{{set: Lower + Upper + Digits, symbols: true}}},
+		{"-", []patternElement{{set: "-", literal: true}}},
+		{`\a`, []patternElement{{set: "a", literal: true}}},
+		{`\\`, []patternElement{{set: `\`, literal: true}}},
+		{`\{`, []patternElement{{set: "{", literal: true}}},
+		{"9{3}", []patternElement{{set: Digits}, {set: Digits}, {set: Digits}}},
+		{"a{1}", []patternElement{{set: Lower}}},
+		{"[abc]", []patternElement{{set: "abc"}}},
+		{"[a-f0-9]", []patternElement{{set: "abcdef0123456789"}}},
+		{`[\]-]`, []patternElement{{set: "]-"}}},
+		{"[xy]{2}", []patternElement{{set: "xy"}, {set: "xy"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, err := parsePattern(tt.pattern)
+			if err != nil {
+				t.Fatalf("parsePattern(%q) error: %v", tt.pattern, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parsePattern(%q) = %v, want %v", tt.pattern, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("parsePattern(%q)[%d] = %+v, want %+v", tt.pattern, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParsePatternMaxRepeat(t *testing.T) {
+	got, err := parsePattern("9{1024}")
+	if err != nil {
+		t.Fatalf("parsePattern error: %v", err)
+	}
+	if len(got) != maxPatternRepeat {
+		t.Errorf("parsePattern returned %d elements, want %d", len(got), maxPatternRepeat)
+	}
+}
+
+func TestParsePatternErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		err     string
+	}{
+		{"empty", "", "empty pattern"},
+		{"trailing escape", `a\`, "ends with an escape"},
+		{"unterminated set", "[abc", "unterminated set"},
+		{"unterminated escaped set", `[ab\]`, "unterminated set"},
+		{"empty set", "[]", "empty set"},
+		{"reversed range", "[z-a]", "reversed"},
+		{"set too large", "[\u0000-\U0010FFFF]", "more than 256"},
+		{"set too large in parts", "[Ā-ǿa-zȀ-˿]", "more than 256"},
+		{"unterminated repetition", "a{3", "unterminated repetition"},
+		{"nothing to repeat", "{3}a", "nothing to repeat"},
+		{"zero repetitions", "a{0}", "invalid repetition count"},
+		{"too many repetitions", "a{1025}", "invalid repetition count"},
+		{"negative repetitions", "a{-1}", "invalid repetition count"},
+		{"non numeric repetitions", "a{x}", "invalid repetition count"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parsePattern(tt.pattern)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parsePattern(%q) error = %v, want it to contain %q", tt.pattern, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestGeneratePattern(t *testing.T) {
+	tests := []struct {
+		name   string
+		config PasswordGeneratorConfig
+		check  func(rune) bool
+	}{
+		{
+			name:   "digits",
+			config: PasswordGeneratorConfig{Pattern: "9{8}"},
+			check:  func(r rune) bool { return strings.ContainsRune(Digits, r) },
+		},
+		{
+			name:   "literals",
+			config: PasswordGeneratorConfig{Pattern: "X{4}-X{4}"},
+			check:  func(r rune) bool { return r == '-' || strings.ContainsRune(Upper+Digits, r) },
+		},
+		{
+			name:   "allowed symbols",
+			config: PasswordGeneratorConfig{Pattern: "!{20}", AllowedSymbols: "#@"},
+			check:  func(r rune) bool { return r == '#' || r == '@' },
+		},
+		{
+			name:   "any with allowed symbols",
+			config: PasswordGeneratorConfig{Pattern: "*{40}", AllowedSymbols: "#"},
+			check:  func(r rune) bool { return r == '#' || strings.ContainsRune(Lower+Upper+Digits, r) },
+		},
+		{
+			name:   "excluded",
+			config: PasswordGeneratorConfig{Pattern: "[0-9a-f]{20}", Exclude: "0a"},
+			check:  func(r rune) bool { return strings.ContainsRune("123456789bcdef", r) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password, err := NewPasswordGenerator(tt.config).Generate()
+			if err != nil {
+				t.Fatalf("Generate error: %v", err)
+			}
+			for _, r := range password {
+				if !tt.check(r) {
+					t.Errorf("Generate() = %q, unexpected character %q", password, r)
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratePatternErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config PasswordGeneratorConfig
+		err    string
+	}{
+		{"below minimum entropy", PasswordGeneratorConfig{Pattern: "9999"}, "at least 18 are needed"},
+		{"literals only", PasswordGeneratorConfig{Pattern: "-_-_-"}, "at least 18 are needed"},
+		{"excluded set", PasswordGeneratorConfig{Pattern: "a{8}[01]", Exclude: "01"}, "element 9 is excluded"},
+		{"excluded symbols", PasswordGeneratorConfig{Pattern: "a{8}!", AllowedSymbols: "#", Exclude: "#"}, "element 9 is excluded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPasswordGenerator(tt.config).Generate()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Generate error = %v, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
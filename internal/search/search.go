@@ -0,0 +1,128 @@
+package search
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	boundaryBonus    = 8
+	consecutiveBonus = 4
+	basenameBonus    = 2
+	maxGapPenalty    = 3
+)
+
+// Result is a service matched by Fuzzy along with its score. Higher scores are better matches.
+type Result struct {
+	Service string `json:"service"`
+	Score   int    `json:"score"`
+}
+
+// Match returns the services containing any of terms, ignoring case, in their original order.
+func Match(services, terms []string) []string {
+	var matches []string
+	for _, service := range services {
+		lower := strings.ToLower(service)
+		for _, term := range terms {
+			if strings.Contains(lower, strings.ToLower(term)) {
+				matches = append(matches, service)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// Fuzzy returns the services in which every term appears as a subsequence, best match first.
+// Characters matched at the start of a path segment or word, in a run, or in the last path
+// segment score higher, so "gh" ranks "dev/github" above "dev/bright".
+func Fuzzy(services, terms []string) []Result {
+	var results []Result
+	for _, service := range services {
+		total := 0
+		matched := true
+		for _, term := range terms {
+			score, ok := fuzzyScore(service, term)
+			if !ok {
+				matched = false
+				break
+			}
+			total += score
+		}
+		if matched {
+			results = append(results, Result{Service: service, Score: total})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return len(results[i].Service) < len(results[j].Service)
+	})
+
+	return results
+}
+
+// fuzzyScore returns the best score of matching term as a subsequence of service.
+func fuzzyScore(service, term string) (int, bool) {
+	s := []rune(strings.ToLower(service))
+	q := []rune(strings.ToLower(term))
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	basename := len(s) - len([]rune(path.Base(service)))
+
+	// best[j] is the best score with the current term character matched at s[j]
+	const none = -1 << 30
+	best := make([]int, len(s))
+	for i := range q {
+		next := make([]int, len(s))
+		for j := range s {
+			next[j] = none
+			if s[j] != q[i] {
+				continue
+			}
+
+			score := 1
+			if j == 0 || strings.ContainsRune("/-_. ", s[j-1]) {
+				score += boundaryBonus
+			}
+			if j >= basename {
+				score += basenameBonus
+			}
+
+			if i == 0 {
+				next[j] = score
+				continue
+			}
+
+			previous := none
+			for k := 0; k < j; k++ {
+				if best[k] == none {
+					continue
+				}
+				candidate := best[k]
+				if k == j-1 {
+					candidate += consecutiveBonus
+				} else {
+					candidate -= min(j-k-1, maxGapPenalty)
+				}
+				previous = max(previous, candidate)
+			}
+			if previous != none {
+				next[j] = previous + score
+			}
+		}
+		best = next
+	}
+
+	result := none
+	for _, score := range best {
+		result = max(result, score)
+	}
+
+	return result, result != none
+}
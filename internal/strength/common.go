@@ -0,0 +1,10 @@
+package strength
+
+// commonPasswords are among the most used passwords, most common first.
+var commonPasswords = []string{
+	"123456", "password", "123456789", "12345678", "12345", "qwerty", "1234567", "111111", "123123", "abc123",
+	"1234567890", "password1", "iloveyou", "000000", "1q2w3e4r", "qwertyuiop", "monkey", "dragon", "123321", "654321",
+	"letmein", "sunshine", "princess", "football", "baseball", "welcome", "shadow", "superman", "master", "michael",
+	"trustno1", "passw0rd", "starwars", "whatever", "freedom", "jennifer", "hunter", "ashley", "charlie", "computer",
+	"admin", "login", "secret", "hello", "changeme", "default", "guest", "test", "love", "pass",
+}
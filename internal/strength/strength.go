@@ -0,0 +1,274 @@
+package strength
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/torbenconto/gopwd/internal/pwgen"
+)
+
+// Score levels, from trivially guessable to resistant to offline attacks.
+const (
+	VeryWeak = iota
+	Weak
+	Fair
+	Strong
+	VeryStrong
+)
+
+// ScoreNames are the names of the score levels, indexed by score.
+var ScoreNames = []string{"very weak", "weak", "fair", "strong", "very strong"}
+
+// scoreBits are the estimated entropies at which the scores above VeryWeak begin.
+var scoreBits = []float64{28, 40, 60, 80}
+
+// Result is the estimated strength of a password.
+type Result struct {
+	// Bits is the estimated entropy: log2 of the guesses an attacker who knows the patterns
+	// below needs.
+	Bits float64 `json:"bits"`
+	// Score rates Bits from VeryWeak to VeryStrong.
+	Score int `json:"score"`
+	// Patterns describes the guessable parts that were found.
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// match is a guessable part of a password from start up to end.
+type match struct {
+	start, end int
+	bits       float64
+	pattern    string
+}
+
+var dictionary = buildDictionary()
+
+// maxMatchLength bounds the length of the parts of a password that are tried as matches, so
+// long secrets are estimated in linear time. Longer repeats and sequences are covered by
+// several matches.
+var maxMatchLength = longestPattern()
+
+func longestPattern() int {
+	longest := 0
+	for word := range dictionary {
+		longest = max(longest, len([]rune(word)))
+	}
+	for _, row := range keyboardRows {
+		longest = max(longest, len(row))
+	}
+	return longest
+}
+
+func buildDictionary() map[string]int {
+	words := map[string]int{}
+	for i, password := range commonPasswords {
+		words[password] = i + 1
+	}
+	for i, word := range pwgen.Words() {
+		if _, ok := words[word]; !ok {
+			words[word] = len(commonPasswords) + i + 1
+		}
+	}
+	return words
+}
+
+// Estimate estimates the strength of password without any network access. Like zxcvbn, it
+// finds dictionary words from the BIP39 wordlist and common passwords (also capitalised or in
+// l33t speak), sequences, repeats, keyboard walks and years, and charges brute force for the
+// rest. The cheapest way to cover the whole password is its estimated entropy.
+func Estimate(password string) Result {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return Result{Score: VeryWeak}
+	}
+
+	// endingAt[i] are the matches ending at rune i
+	endingAt := make([][]match, len(runes)+1)
+	for _, m := range findMatches(runes) {
+		endingAt[m.end] = append(endingAt[m.end], m)
+	}
+	charBits := math.Log2(float64(cardinality(runes)))
+
+	// best[i] is the cheapest cover of runes[:i] and via[i] the match ending it, if any
+	best := make([]float64, len(runes)+1)
+	via := make([]*match, len(runes)+1)
+	for i := 1; i <= len(runes); i++ {
+		best[i] = best[i-1] + charBits
+		via[i] = nil
+		for j := range endingAt[i] {
+			m := &endingAt[i][j]
+			// Each extra pattern costs a bit for the attacker to choose how to combine them
+			if bits := best[m.start] + m.bits + 1; bits < best[i] {
+				best[i] = bits
+				via[i] = m
+			}
+		}
+	}
+
+	result := Result{Bits: math.Round(best[len(runes)]*10) / 10}
+	for i := len(runes); i > 0; {
+		if m := via[i]; m != nil {
+			result.Patterns = append([]string{m.pattern}, result.Patterns...)
+			i = m.start
+			continue
+		}
+		i--
+	}
+
+	for _, bits := range scoreBits {
+		if result.Bits >= bits {
+			result.Score++
+		}
+	}
+
+	return result
+}
+
+func findMatches(runes []rune) []match {
+	var matches []match
+	lower := []rune(strings.ToLower(string(runes)))
+	unleeted := unleet(lower)
+
+	for i := range runes {
+		for j := i + 3; j <= min(len(runes), i+maxMatchLength); j++ {
+			word := string(lower[i:j])
+
+			// Dictionary words, plainly, with capitals or with l33t substitutions
+			candidate := word
+			variations := 0.0
+			if _, ok := dictionary[candidate]; !ok && j-i >= 4 {
+				candidate = string(unleeted[i:j])
+				variations = 1
+			}
+			if rank, ok := dictionary[candidate]; ok {
+				if string(runes[i:j]) != word {
+					variations++
+				}
+				matches = append(matches, match{i, j, math.Log2(float64(rank)) + variations, fmt.Sprintf("dictionary word %q", candidate)})
+			}
+
+			segment := runes[i:j]
+			if isRepeat(segment) {
+				matches = append(matches, match{i, j, math.Log2(float64(cardinality(segment[:1])) * float64(len(segment))), fmt.Sprintf("repeated character %q", string(segment[:1]))})
+			}
+			if isSequence(segment) {
+				matches = append(matches, match{i, j, math.Log2(26 * float64(len(segment))), fmt.Sprintf("sequence %q", string(segment))})
+			}
+			if len(segment) >= 4 && isKeyboardWalk(string(lower[i:j])) {
+				matches = append(matches, match{i, j, math.Log2(100 * float64(len(segment))), fmt.Sprintf("keyboard pattern %q", string(segment))})
+			}
+			if len(segment) == 4 && isYear(string(segment)) {
+				matches = append(matches, match{i, j, math.Log2(200), fmt.Sprintf("year %q", string(segment))})
+			}
+		}
+	}
+
+	return matches
+}
+
+// cardinality returns the size of the character classes used in runes.
+func cardinality(runes []rune) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	size := 0
+	if lower {
+		size += 26
+	}
+	if upper {
+		size += 26
+	}
+	if digit {
+		size += 10
+	}
+	if symbol {
+		size += 33
+	}
+	if other {
+		size += 100
+	}
+	return size
+}
+
+var leet = map[rune]rune{'4': 'a', '@': 'a', '8': 'b', '3': 'e', '6': 'g', '1': 'i', '!': 'i', '0': 'o', '5': 's', '$': 's', '7': 't', '2': 'z'}
+
+func unleet(runes []rune) []rune {
+	result := make([]rune, len(runes))
+	for i, r := range runes {
+		if plain, ok := leet[r]; ok {
+			r = plain
+		}
+		result[i] = r
+	}
+	return result
+}
+
+func isRepeat(runes []rune) bool {
+	for _, r := range runes[1:] {
+		if r != runes[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// isSequence reports whether runes step through the alphabet or digits by one, either way.
+func isSequence(runes []rune) bool {
+	for _, r := range runes {
+		if !isAlnum(r) {
+			return false
+		}
+	}
+
+	step := runes[1] - runes[0]
+	if step != 1 && step != -1 {
+		return false
+	}
+	for i := 2; i < len(runes); i++ {
+		if runes[i]-runes[i-1] != step {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+var keyboardRows = []string{"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./", "qazwsxedcrfvtgbyhnujmikolp"}
+
+func isKeyboardWalk(s string) bool {
+	for _, row := range keyboardRows {
+		if strings.Contains(row, s) || strings.Contains(reverse(row), s) {
+			return true
+		}
+	}
+	return false
+}
+
+func isYear(s string) bool {
+	return (strings.HasPrefix(s, "19") || strings.HasPrefix(s, "20")) && strings.Trim(s, "0123456789") == ""
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
@@ -2,6 +2,7 @@ package termio
 
 import (
 	"fmt"
+	"os"
 	"syscall"
 
 	"golang.org/x/term"
@@ -30,3 +31,16 @@ func PromptPassword() (string, error) {
 
 	return string(password), nil
 }
+
+// ReadPassphrase prompts once on stderr and reads a passphrase without echoing it.
+func ReadPassphrase(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Fprintln(os.Stderr)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(passphrase), nil
+}
@@ -6,6 +6,8 @@ import (
 	"io"
 	"os"
 	"strings"
+
+	"golang.org/x/term"
 )
 
 func ReadMultiline() (string, error) {
@@ -34,3 +36,8 @@ func ReadMultiline() (string, error) {
 
 	return input.String(), nil
 }
+
+// IsTerminal reports whether f is connected to a terminal.
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
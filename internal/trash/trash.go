@@ -0,0 +1,185 @@
+package trash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/torbenconto/gopwd/internal/io"
+)
+
+// Dir is the hidden directory inside the vault that holds deleted entries. Each deletion
+// gets its own directory, named after the deletion time, below which the entry keeps its
+// original path.
+const Dir = ".trash"
+
+// Item is a deleted entry.
+type Item struct {
+	ID        string    `json:"id"`
+	Service   string    `json:"service"`
+	DeletedAt time.Time `json:"deleted_at"`
+	Path      string    `json:"-"`
+}
+
+// Move puts the entry at servicePath into the trash.
+func Move(vaultPath, service, servicePath string) error {
+	id := strconv.FormatInt(time.Now().UnixNano(), 10)
+	target := filepath.Join(vaultPath, Dir, id, filepath.FromSlash(service)+filepath.Ext(servicePath))
+
+	err := os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create trash directory: %v", err)
+	}
+
+	return os.Rename(servicePath, target)
+}
+
+// List returns the deleted entries, most recently deleted first.
+func List(vaultPath string) ([]Item, error) {
+	root := filepath.Join(vaultPath, Dir)
+
+	ids, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	for _, id := range ids {
+		nanos, err := strconv.ParseInt(id.Name(), 10, 64)
+		if err != nil || !id.IsDir() {
+			continue
+		}
+
+		services, err := io.ListServices(filepath.Join(root, id.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, service := range services {
+			path, err := entryPath(filepath.Join(root, id.Name()), service)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, Item{
+				ID:        id.Name(),
+				Service:   filepath.ToSlash(service),
+				DeletedAt: time.Unix(0, nanos),
+				Path:      path,
+			})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+
+	return items, nil
+}
+
+// entryPath finds the file of service below dir, whatever its extension.
+func entryPath(dir, service string) (string, error) {
+	for _, ext := range io.Extensions() {
+		path := filepath.Join(dir, service) + ext
+		if io.Exists(path) {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("entry %s not found in trash", service)
+}
+
+// Find returns the most recently deleted copy of service, or the copy with the given ID.
+func Find(vaultPath, service, id string) (Item, error) {
+	items, err := List(vaultPath)
+	if err != nil {
+		return Item{}, err
+	}
+
+	for _, item := range items {
+		if item.Service == service && (id == "" || item.ID == id) {
+			return item, nil
+		}
+	}
+
+	return Item{}, fmt.Errorf("service %s not found in trash", service)
+}
+
+// Restore moves item back to its original path in the vault.
+func Restore(vaultPath string, item Item) (string, error) {
+	target := filepath.Join(vaultPath, filepath.FromSlash(item.Service)) + filepath.Ext(item.Path)
+	if io.Exists(target) {
+		return "", fmt.Errorf("service %s already exists", item.Service)
+	}
+
+	err := os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.Rename(item.Path, target)
+	if err != nil {
+		return "", err
+	}
+
+	return target, removeEmptyParents(vaultPath, item.Path)
+}
+
+// Purge deletes items for good.
+func Purge(vaultPath string, items []Item) error {
+	for _, item := range items {
+		err := os.Remove(item.Path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to purge %s: %v", item.Service, err)
+		}
+		err = removeEmptyParents(vaultPath, item.Path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PurgeOlderThan deletes the items that have been in the trash for longer than maxAge and
+// returns how many were deleted.
+func PurgeOlderThan(vaultPath string, maxAge time.Duration) (int, error) {
+	items, err := List(vaultPath)
+	if err != nil {
+		return 0, err
+	}
+
+	var expired []Item
+	for _, item := range items {
+		if time.Since(item.DeletedAt) > maxAge {
+			expired = append(expired, item)
+		}
+	}
+
+	return len(expired), Purge(vaultPath, expired)
+}
+
+// removeEmptyParents removes the directories left empty above path, up to the trash root.
+func removeEmptyParents(vaultPath, path string) error {
+	root := filepath.Join(vaultPath, Dir)
+
+	for dir := filepath.Dir(path); dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		isEmpty, err := io.IsDirEmpty(dir)
+		if err != nil {
+			return err
+		}
+		if !isEmpty {
+			break
+		}
+		err = os.Remove(dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
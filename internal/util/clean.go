@@ -6,6 +6,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/torbenconto/gopwd/internal/history"
 	"github.com/torbenconto/gopwd/internal/io"
 )
 
@@ -25,6 +26,12 @@ func CreateStructureAndClean(service, vaultPath, servicePath string, encrypted [
 		}
 	}()
 
+	// Keep the previous version if an existing entry is overwritten
+	err := history.Save(vaultPath, service, servicePath)
+	if err != nil {
+		return err
+	}
+
 	dirs := strings.Split(service, "/")
 	var dirPath string
 	if len(dirs) > 1 {
@@ -38,9 +45,9 @@ func CreateStructureAndClean(service, vaultPath, servicePath string, encrypted [
 		}
 	}
 
-	_, err := io.CreateFile(servicePath)
+	_, err = io.CreateFile(servicePath)
 	if err != nil {
-		return fmt.Errorf("failed to create .gpg file for service: %s, error: %v", service, err)
+		return fmt.Errorf("failed to create entry file for service: %s, error: %v", service, err)
 	}
 	createdFiles = append(createdFiles, servicePath)
 
@@ -1,28 +1,249 @@
 package util
 
 import (
+	"bufio"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/crypt/age"
+	"github.com/torbenconto/gopwd/internal/io"
 )
 
-func ReadGPGID(path string) (string, error) {
+// ReadGPGID reads a recipients file such as .gpg-id, which holds one key ID per line.
+// Blank lines and lines starting with # are skipped.
+func ReadGPGID(path string) ([]string, error) {
 	file, err := os.Open(path)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer file.Close()
 
-	gpgIDBytes := make([]byte, 0)
-	buffer := make([]byte, 4096)
-	for {
-		n, err := file.Read(buffer)
-		if n > 0 {
-			gpgIDBytes = append(gpgIDBytes, buffer[:n]...)
+	var ids []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
 		}
-		if err != nil {
+		ids = append(ids, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no recipients in %s", path)
+	}
+
+	return ids, nil
+}
+
+// BackendName returns the backend selected by the "backend" key in .gopwd.yaml. Vaults
+// without that key are detected from their recipients file.
+func BackendName(vaultPath string) string {
+	if name := viper.GetString("backend"); name != "" {
+		return name
+	}
+
+	if format, err := crypt.FormatOf(age.Name); err == nil && io.Exists(filepath.Join(vaultPath, format.RecipientsFile)) {
+		return age.Name
+	}
+
+	return crypt.DefaultBackend
+}
+
+// VaultFormat returns the on-disk format of the vault's backend.
+func VaultFormat(vaultPath string) (crypt.Format, error) {
+	return crypt.FormatOf(BackendName(vaultPath))
+}
+
+// ServicePath returns the file that stores service, using the extension of the vault's backend.
+func ServicePath(vaultPath, service string) string {
+	format, err := VaultFormat(vaultPath)
+	if err != nil {
+		format.Extension = ".gpg"
+	}
+
+	return filepath.Join(vaultPath, service) + format.Extension
+}
+
+// RecipientsFile returns the recipients file that applies to service: the nearest one
+// found walking up from the service's directory to the vault root.
+func RecipientsFile(vaultPath, service string) (string, error) {
+	format, err := VaultFormat(vaultPath)
+	if err != nil {
+		return "", err
+	}
+
+	vaultPath = filepath.Clean(vaultPath)
+	root := filepath.Join(vaultPath, format.RecipientsFile)
+
+	dir := filepath.Dir(filepath.Join(vaultPath, service))
+	for dir != vaultPath {
+		rel, err := filepath.Rel(vaultPath, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
 			break
 		}
+		if file := filepath.Join(dir, format.RecipientsFile); io.Exists(file) {
+			return file, nil
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	return root, nil
+}
+
+// NewBackend returns the vault's encryption backend, set up to encrypt service for the
+// recipients in its nearest recipients file (.gpg-id for GPG vaults).
+func NewBackend(vaultPath, service string, config crypt.Config) (crypt.Backend, error) {
+	name := BackendName(vaultPath)
+
+	file, err := RecipientsFile(vaultPath, service)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients, err := ReadGPGID(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", filepath.Base(file), err)
+	}
+	config.Recipients = recipients
+	if config.Keyring == "" {
+		config.Keyring = viper.GetString("keyring")
+	}
+
+	return crypt.New(name, config)
+}
+
+// DecryptService reads and decrypts the entry of service. The backend is returned so the
+// entry can be written back for the same recipients.
+func DecryptService(vaultPath, service string, config crypt.Config) ([]byte, crypt.Backend, error) {
+	servicePath := ServicePath(vaultPath, service)
+	if !io.Exists(servicePath) {
+		return nil, nil, fmt.Errorf("service %s not found", service)
+	}
+
+	file, err := io.ReadFile(servicePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	backend, err := NewBackend(vaultPath, service, config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load encryption backend: %v", err)
+	}
+
+	plaintext, err := backend.Decrypt(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt password: %v", err)
+	}
+
+	return plaintext, backend, nil
+}
+
+// EncryptService encrypts plaintext with backend and replaces the entry of service in one step.
+func EncryptService(vaultPath, service string, backend crypt.Backend, plaintext []byte) error {
+	encrypted, err := backend.Encrypt(plaintext)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt password: %v", err)
+	}
+
+	err = io.WriteFileAtomic(ServicePath(vaultPath, service), encrypted)
+	if err != nil {
+		return fmt.Errorf("failed to write encrypted password to file: %v", err)
+	}
+
+	return nil
+}
+
+// SameRecipients reports whether service and newService are encrypted for the same recipients
+// file, so the entry of one can be moved to the other as it is.
+func SameRecipients(vaultPath, service, newService string) (bool, error) {
+	file, err := RecipientsFile(vaultPath, service)
+	if err != nil {
+		return false, err
+	}
+	newFile, err := RecipientsFile(vaultPath, newService)
+	if err != nil {
+		return false, err
+	}
+	return file == newFile, nil
+}
+
+// CopyService copies the entry of service to newService. The ciphertext is copied as it is when
+// both use the same recipients file, otherwise the entry is decrypted and encrypted for the
+// recipients of newService.
+func CopyService(vaultPath, service, newService string, config crypt.Config) error {
+	newServicePath := ServicePath(vaultPath, newService)
+	err := os.MkdirAll(filepath.Dir(newServicePath), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create directory structure for service: %s, error: %v", newService, err)
+	}
+
+	same, err := SameRecipients(vaultPath, service, newService)
+	if err != nil {
+		return err
+	}
+	if same {
+		return io.CopyFile(ServicePath(vaultPath, service), newServicePath)
+	}
+
+	plaintext, _, err := DecryptService(vaultPath, service, config)
+	if err != nil {
+		return err
+	}
+
+	backend, err := NewBackend(vaultPath, newService, config)
+	if err != nil {
+		return fmt.Errorf("failed to load encryption backend: %v", err)
+	}
+
+	return EncryptService(vaultPath, newService, backend, plaintext)
+}
+
+// BackendCache shares one backend between the entries that use the same recipients file, for
+// commands that work through many entries at once. It is safe for concurrent use.
+type BackendCache struct {
+	vaultPath string
+	config    crypt.Config
+
+	mu       sync.Mutex
+	backends map[string]crypt.Backend
+}
+
+// NewBackendCache returns a BackendCache creating backends for vaultPath with config.
+func NewBackendCache(vaultPath string, config crypt.Config) *BackendCache {
+	return &BackendCache{
+		vaultPath: vaultPath,
+		config:    config,
+		backends:  map[string]crypt.Backend{},
+	}
+}
+
+// Get returns the backend for service.
+func (c *BackendCache) Get(service string) (crypt.Backend, error) {
+	file, err := RecipientsFile(c.vaultPath, service)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if backend, ok := c.backends[file]; ok {
+		return backend, nil
+	}
+
+	backend, err := NewBackend(c.vaultPath, service, c.config)
+	if err != nil {
+		return nil, err
 	}
+	c.backends[file] = backend
 
-	return strings.TrimSpace(string(gpgIDBytes)), nil
+	return backend, nil
 }
@@ -0,0 +1,70 @@
+package util
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/torbenconto/gopwd/internal/crypt"
+)
+
+// plaintextBackend stores entries as they are, so vault code can be tested without a keyring.
+type plaintextBackend struct {
+	recipients []string
+}
+
+func (b *plaintextBackend) Encrypt(plaintext []byte) ([]byte, error) {
+	return append([]byte("plaintext:"), plaintext...), nil
+}
+
+func (b *plaintextBackend) Decrypt(ciphertext []byte) ([]byte, error) {
+	return bytes.TrimPrefix(ciphertext, []byte("plaintext:")), nil
+}
+
+func (b *plaintextBackend) Recipients() []string { return b.recipients }
+func (b *plaintextBackend) Name() string         { return "plaintext" }
+
+func init() {
+	crypt.Register("plaintext", crypt.Format{Extension: ".txt", RecipientsFile: ".txt-id"}, func(config crypt.Config) (crypt.Backend, error) {
+		return &plaintextBackend{recipients: config.Recipients}, nil
+	})
+}
+
+func TestNewBackendRoundTrip(t *testing.T) {
+	viper.Set("backend", "plaintext")
+	t.Cleanup(func() { viper.Set("backend", "") })
+
+	vaultPath := t.TempDir()
+	err := os.WriteFile(filepath.Join(vaultPath, ".txt-id"), []byte("alice@example.com\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend, err := NewBackend(vaultPath, "github", crypt.Config{})
+	if err != nil {
+		t.Fatalf("NewBackend error: %v", err)
+	}
+	if backend.Name() != "plaintext" || !slices.Equal(backend.Recipients(), []string{"alice@example.com"}) {
+		t.Fatalf("NewBackend = %s for %v, want plaintext for alice@example.com", backend.Name(), backend.Recipients())
+	}
+
+	if got := ServicePath(vaultPath, "github"); got != filepath.Join(vaultPath, "github.txt") {
+		t.Errorf("ServicePath = %s, want the extension of the backend", got)
+	}
+
+	err = EncryptService(vaultPath, "github", backend, []byte("hunter2\nusername: alice"))
+	if err != nil {
+		t.Fatalf("EncryptService error: %v", err)
+	}
+	plaintext, _, err := DecryptService(vaultPath, "github", crypt.Config{})
+	if err != nil {
+		t.Fatalf("DecryptService error: %v", err)
+	}
+	if string(plaintext) != "hunter2\nusername: alice" {
+		t.Errorf("DecryptService = %q, want the encrypted entry", plaintext)
+	}
+}
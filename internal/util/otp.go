@@ -0,0 +1,47 @@
+package util
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/entry"
+	"github.com/torbenconto/gopwd/internal/otp"
+)
+
+// NextOTP returns the key and current one-time code stored in the entry of service.
+// HOTP counters are incremented and the entry is re-encrypted before the code is returned.
+func NextOTP(vaultPath, service string, config crypt.Config) (*otp.Key, string, error) {
+	content, backend, err := DecryptService(vaultPath, service, config)
+	if err != nil {
+		return nil, "", err
+	}
+
+	parsed := entry.Parse(content)
+	line, ok := otp.FindLine(parsed.Lines())
+	if !ok {
+		return nil, "", fmt.Errorf("no otpauth URI found for service %s", service)
+	}
+
+	key, err := otp.ParseURI(parsed.Lines()[line])
+	if err != nil {
+		return nil, "", err
+	}
+
+	code, err := key.Code(time.Now())
+	if err != nil {
+		return nil, "", err
+	}
+
+	if key.Type == otp.HOTP {
+		key.Counter++
+		parsed.SetLine(line, key.URI())
+
+		err = EncryptService(vaultPath, service, backend, parsed.Bytes())
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to save HOTP counter: %v", err)
+		}
+	}
+
+	return key, code, nil
+}
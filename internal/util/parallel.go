@@ -0,0 +1,52 @@
+package util
+
+import (
+	"runtime"
+	"sync"
+)
+
+// DefaultWorkers is the number of entries processed at once by vault-wide commands.
+var DefaultWorkers = runtime.NumCPU()
+
+// ForEachService calls fn for every service using at most workers goroutines. progress,
+// if not nil, is called after each service finishes, one call at a time. The errors
+// returned by fn are collected by service.
+func ForEachService(services []string, workers int, fn func(service string) error, progress func(done, total int, service string, err error)) map[string]error {
+	if workers < 1 {
+		workers = DefaultWorkers
+	}
+
+	jobs := make(chan string)
+	errs := map[string]error{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for service := range jobs {
+				err := fn(service)
+
+				mu.Lock()
+				done++
+				if err != nil {
+					errs[service] = err
+				}
+				if progress != nil {
+					progress(done, len(services), service, err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, service := range services {
+		jobs <- service
+	}
+	close(jobs)
+	wg.Wait()
+
+	return errs
+}
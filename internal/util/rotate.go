@@ -0,0 +1,78 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/torbenconto/gopwd/internal/crypt"
+	"github.com/torbenconto/gopwd/internal/entry"
+	"github.com/torbenconto/gopwd/internal/history"
+	"github.com/torbenconto/gopwd/internal/io"
+)
+
+// ReplacePassword replaces the first line of the entry of service with password, keeping the
+// rest of the entry byte for byte and saving the previous version to the history. It returns
+// when the old password was set.
+func ReplacePassword(vaultPath, service, password string, config crypt.Config) (time.Time, error) {
+	servicePath := ServicePath(vaultPath, service)
+	info, err := os.Stat(servicePath)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("service %s not found", service)
+	}
+
+	content, backend, err := DecryptService(vaultPath, service, config)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	setAt := passwordSetAt(vaultPath, service, entry.Parse(content).Password(), backend, info.ModTime())
+
+	// Keep a \r of a CRLF first line with the rest of the entry
+	rest := []byte{}
+	if i := bytes.IndexByte(content, '\n'); i >= 0 {
+		if i > 0 && content[i-1] == '\r' {
+			i--
+		}
+		rest = content[i:]
+	}
+
+	err = history.Save(vaultPath, service, servicePath)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	err = EncryptService(vaultPath, service, backend, append([]byte(password), rest...))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return setAt, nil
+}
+
+// passwordSetAt returns when password became the first line of the entry of service: when the
+// newest saved version with another password was replaced. The entry's modification time is
+// not used unless there is no history, since editing other lines, re-encrypting or checking
+// out the vault change it too.
+func passwordSetAt(vaultPath, service, password string, backend crypt.Backend, modified time.Time) time.Time {
+	versions, err := history.List(vaultPath, service)
+	if err != nil || len(versions) == 0 {
+		return modified
+	}
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		// A version that can't be read or decrypted is counted as another password
+		ciphertext, err := io.ReadFile(versions[i].Path)
+		if err != nil {
+			return versions[i].Replaced()
+		}
+		plaintext, err := backend.Decrypt(ciphertext)
+		if err != nil || entry.Parse(plaintext).Password() != password {
+			return versions[i].Replaced()
+		}
+	}
+
+	// Every saved version has this password, so it is at least as old as the oldest
+	return versions[0].Time
+}
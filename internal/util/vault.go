@@ -4,28 +4,39 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/torbenconto/gopwd/internal/io"
+	"github.com/torbenconto/gopwd/internal/trash"
 )
 
 func PrintVaultStructure(vaultPath string) error {
 	var printStructure func(path string, prefix string, isLast bool)
 	printStructure = func(path string, prefix string, isLast bool) {
-		dirEntries, err := os.ReadDir(path)
+		allEntries, err := os.ReadDir(path)
 		if err != nil {
 			fmt.Println("Error reading directory:", err)
 			return
 		}
 
-		for i, entry := range dirEntries {
-			if entry.Name() == ".gpg-id" {
-				continue
+		// Skip recipients files and other hidden entries
+		var dirEntries []os.DirEntry
+		for _, entry := range allEntries {
+			if !strings.HasPrefix(entry.Name(), ".") {
+				dirEntries = append(dirEntries, entry)
 			}
+		}
 
+		for i, entry := range dirEntries {
 			isLastEntry := i == len(dirEntries)-1
 			entryName := entry.Name()
 
-			if strings.HasSuffix(entryName, ".gpg") {
-				entryName = strings.TrimSuffix(entryName, ".gpg")
+			if !entry.IsDir() {
+				entryName, _ = io.TrimExtension(entryName)
 			}
 
 			var linePrefix string
@@ -41,7 +52,7 @@ func PrintVaultStructure(vaultPath string) error {
 				} else {
 					fmt.Println(prefix + "├── " + entryName)
 				}
-				printStructure(filepath.Join(path, entryName), linePrefix, isLastEntry)
+				printStructure(filepath.Join(path, entry.Name()), linePrefix, isLastEntry)
 			} else {
 				if isLastEntry {
 					fmt.Println(prefix + "└── " + entryName)
@@ -55,3 +66,77 @@ func PrintVaultStructure(vaultPath string) error {
 	printStructure(vaultPath, "", false)
 	return nil
 }
+
+// PrintServiceTree prints services as a tree in the style of PrintVaultStructure, showing only
+// the directories that lead to them.
+func PrintServiceTree(services []string) {
+	type node struct {
+		children map[string]*node
+	}
+	root := &node{children: map[string]*node{}}
+
+	for _, service := range services {
+		current := root
+		for _, part := range strings.Split(filepath.ToSlash(service), "/") {
+			child, ok := current.children[part]
+			if !ok {
+				child = &node{children: map[string]*node{}}
+				current.children[part] = child
+			}
+			current = child
+		}
+	}
+
+	var printNode func(n *node, prefix string)
+	printNode = func(n *node, prefix string) {
+		names := make([]string, 0, len(n.children))
+		for name := range n.children {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for i, name := range names {
+			if i == len(names)-1 {
+				fmt.Println(prefix + "└── " + name)
+				printNode(n.children[name], prefix+"    ")
+			} else {
+				fmt.Println(prefix + "├── " + name)
+				printNode(n.children[name], prefix+"│   ")
+			}
+		}
+	}
+
+	printNode(root, "")
+}
+
+// ListServicesUnder returns the services stored below subtree, relative to the vault root.
+// An empty subtree lists the whole vault and a subtree naming a single service returns it.
+func ListServicesUnder(vaultPath, subtree string) ([]string, error) {
+	subtree = strings.Trim(filepath.Clean("/"+subtree), "/")
+
+	if subtree != "" && io.Exists(ServicePath(vaultPath, subtree)) {
+		return []string{subtree}, nil
+	}
+
+	services, err := io.ListServices(filepath.Join(vaultPath, subtree))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, service := range services {
+		services[i] = filepath.ToSlash(filepath.Join(subtree, service))
+	}
+
+	return services, nil
+}
+
+// AutoPurgeTrash deletes the trashed entries older than the "trashRetentionDays" setting in
+// .gopwd.yaml. Nothing is purged when the setting is missing or 0.
+func AutoPurgeTrash(vaultPath string) (int, error) {
+	days := viper.GetInt("trashRetentionDays")
+	if days <= 0 {
+		return 0, nil
+	}
+
+	return trash.PurgeOlderThan(vaultPath, time.Duration(days)*24*time.Hour)
+}